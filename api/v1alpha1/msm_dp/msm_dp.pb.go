@@ -212,6 +212,223 @@ func (Encap) EnumDescriptor() ([]byte, []int) {
 	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{2}
 }
 
+type LatchMode int32
+
+const (
+	LatchMode_LATCH_NONE      LatchMode = 0
+	LatchMode_LATCH_SOURCE_IP LatchMode = 1
+	LatchMode_LATCH_SSRC      LatchMode = 2
+	LatchMode_LATCH_TOKEN     LatchMode = 3
+)
+
+// Enum value maps for LatchMode.
+var (
+	LatchMode_name = map[int32]string{
+		0: "LATCH_NONE",
+		1: "LATCH_SOURCE_IP",
+		2: "LATCH_SSRC",
+		3: "LATCH_TOKEN",
+	}
+	LatchMode_value = map[string]int32{
+		"LATCH_NONE":      0,
+		"LATCH_SOURCE_IP": 1,
+		"LATCH_SSRC":      2,
+		"LATCH_TOKEN":     3,
+	}
+)
+
+func (x LatchMode) Enum() *LatchMode {
+	p := new(LatchMode)
+	*p = x
+	return p
+}
+
+func (x LatchMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LatchMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[3].Descriptor()
+}
+
+func (LatchMode) Type() protoreflect.EnumType {
+	return &file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[3]
+}
+
+func (x LatchMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LatchMode.Descriptor instead.
+func (LatchMode) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{3}
+}
+
+type VideoCodec int32
+
+const (
+	VideoCodec_CODEC_NONE VideoCodec = 0
+	VideoCodec_H264       VideoCodec = 1
+	VideoCodec_H265       VideoCodec = 2
+)
+
+// Enum value maps for VideoCodec.
+var (
+	VideoCodec_name = map[int32]string{
+		0: "CODEC_NONE",
+		1: "H264",
+		2: "H265",
+	}
+	VideoCodec_value = map[string]int32{
+		"CODEC_NONE": 0,
+		"H264":       1,
+		"H265":       2,
+	}
+)
+
+func (x VideoCodec) Enum() *VideoCodec {
+	p := new(VideoCodec)
+	*p = x
+	return p
+}
+
+func (x VideoCodec) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VideoCodec) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[4].Descriptor()
+}
+
+func (VideoCodec) Type() protoreflect.EnumType {
+	return &file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[4]
+}
+
+func (x VideoCodec) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use VideoCodec.Descriptor instead.
+func (VideoCodec) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{4}
+}
+
+type MediaType int32
+
+const (
+	MediaType_MEDIA_VIDEO MediaType = 0
+	MediaType_MEDIA_AUDIO MediaType = 1
+)
+
+// Enum value maps for MediaType.
+var (
+	MediaType_name = map[int32]string{
+		0: "MEDIA_VIDEO",
+		1: "MEDIA_AUDIO",
+	}
+	MediaType_value = map[string]int32{
+		"MEDIA_VIDEO": 0,
+		"MEDIA_AUDIO": 1,
+	}
+)
+
+func (x MediaType) Enum() *MediaType {
+	p := new(MediaType)
+	*p = x
+	return p
+}
+
+func (x MediaType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MediaType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[5].Descriptor()
+}
+
+func (MediaType) Type() protoreflect.EnumType {
+	return &file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[5]
+}
+
+func (x MediaType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MediaType.Descriptor instead.
+func (MediaType) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{5}
+}
+
+type StreamEventType int32
+
+const (
+	StreamEventType_SOURCE_FAILOVER    StreamEventType = 0
+	StreamEventType_STREAM_STALLED     StreamEventType = 1
+	StreamEventType_STREAM_RESUMED     StreamEventType = 2
+	StreamEventType_STREAM_REMOVED     StreamEventType = 3
+	StreamEventType_CLIENT_TIMEOUT     StreamEventType = 4
+	StreamEventType_CLIENT_UNREACHABLE StreamEventType = 5
+	StreamEventType_STREAM_ENDED       StreamEventType = 6
+	StreamEventType_CLIENT_BYE         StreamEventType = 7
+	StreamEventType_CLIENT_LATCHED     StreamEventType = 8
+	StreamEventType_LATCH_TIMEOUT      StreamEventType = 9
+)
+
+// Enum value maps for StreamEventType.
+var (
+	StreamEventType_name = map[int32]string{
+		0: "SOURCE_FAILOVER",
+		1: "STREAM_STALLED",
+		2: "STREAM_RESUMED",
+		3: "STREAM_REMOVED",
+		4: "CLIENT_TIMEOUT",
+		5: "CLIENT_UNREACHABLE",
+		6: "STREAM_ENDED",
+		7: "CLIENT_BYE",
+		8: "CLIENT_LATCHED",
+		9: "LATCH_TIMEOUT",
+	}
+	StreamEventType_value = map[string]int32{
+		"SOURCE_FAILOVER":    0,
+		"STREAM_STALLED":     1,
+		"STREAM_RESUMED":     2,
+		"STREAM_REMOVED":     3,
+		"CLIENT_TIMEOUT":     4,
+		"CLIENT_UNREACHABLE": 5,
+		"STREAM_ENDED":       6,
+		"CLIENT_BYE":         7,
+		"CLIENT_LATCHED":     8,
+		"LATCH_TIMEOUT":      9,
+	}
+)
+
+func (x StreamEventType) Enum() *StreamEventType {
+	p := new(StreamEventType)
+	*p = x
+	return p
+}
+
+func (x StreamEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StreamEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[6].Descriptor()
+}
+
+func (StreamEventType) Type() protoreflect.EnumType {
+	return &file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[6]
+}
+
+func (x StreamEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StreamEventType.Descriptor instead.
+func (StreamEventType) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{6}
+}
+
 type HealthCheckResponse_ServingStatus int32
 
 const (
@@ -248,11 +465,11 @@ func (x HealthCheckResponse_ServingStatus) String() string {
 }
 
 func (HealthCheckResponse_ServingStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[3].Descriptor()
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[7].Descriptor()
 }
 
 func (HealthCheckResponse_ServingStatus) Type() protoreflect.EnumType {
-	return &file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[3]
+	return &file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes[7]
 }
 
 func (x HealthCheckResponse_ServingStatus) Number() protoreflect.EnumNumber {
@@ -261,7 +478,7 @@ func (x HealthCheckResponse_ServingStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthCheckResponse_ServingStatus.Descriptor instead.
 func (HealthCheckResponse_ServingStatus) EnumDescriptor() ([]byte, []int) {
-	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{4, 0}
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{20, 0}
 }
 
 type Endpoint struct {
@@ -273,6 +490,8 @@ type Endpoint struct {
 	Port       uint32 `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
 	QuicStream uint32 `protobuf:"varint,3,opt,name=quic_stream,json=quicStream,proto3" json:"quic_stream,omitempty"`
 	Encap      uint32 `protobuf:"varint,4,opt,name=encap,proto3" json:"encap,omitempty"`
+	// keys of the SRTP and SRTCP packets exchanged with the endpoint, clear RTP if not set
+	Srtp *SrtpCrypto `protobuf:"bytes,5,opt,name=srtp,proto3" json:"srtp,omitempty"`
 }
 
 func (x *Endpoint) Reset() {
@@ -335,35 +554,1476 @@ func (x *Endpoint) GetEncap() uint32 {
 	return 0
 }
 
-type StreamData struct {
+func (x *Endpoint) GetSrtp() *SrtpCrypto {
+	if x != nil {
+		return x.Srtp
+	}
+	return nil
+}
+
+// SDES crypto attributes of an endpoint, as in RFC 4568
+type SrtpCrypto struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// crypto suite, AES_CM_128_HMAC_SHA1_80 or AES_CM_128_HMAC_SHA1_32
+	Suite string `protobuf:"bytes,1,opt,name=suite,proto3" json:"suite,omitempty"`
+	// "inline:" followed by the base64 master key and salt, of the packets
+	// exchanged in both directions unless they have their own keys
+	KeyParams string `protobuf:"bytes,2,opt,name=key_params,json=keyParams,proto3" json:"key_params,omitempty"`
+	// key params of the packets received from the endpoint, key_params if not set
+	InboundKeyParams string `protobuf:"bytes,3,opt,name=inbound_key_params,json=inboundKeyParams,proto3" json:"inbound_key_params,omitempty"`
+	// key params of the packets sent to the endpoint, key_params if not set
+	OutboundKeyParams string `protobuf:"bytes,4,opt,name=outbound_key_params,json=outboundKeyParams,proto3" json:"outbound_key_params,omitempty"`
+}
+
+func (x *SrtpCrypto) Reset() {
+	*x = SrtpCrypto{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SrtpCrypto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SrtpCrypto) ProtoMessage() {}
+
+func (x *SrtpCrypto) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SrtpCrypto.ProtoReflect.Descriptor instead.
+func (*SrtpCrypto) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SrtpCrypto) GetSuite() string {
+	if x != nil {
+		return x.Suite
+	}
+	return ""
+}
+
+func (x *SrtpCrypto) GetKeyParams() string {
+	if x != nil {
+		return x.KeyParams
+	}
+	return ""
+}
+
+func (x *SrtpCrypto) GetInboundKeyParams() string {
+	if x != nil {
+		return x.InboundKeyParams
+	}
+	return ""
+}
+
+func (x *SrtpCrypto) GetOutboundKeyParams() string {
+	if x != nil {
+		return x.OutboundKeyParams
+	}
+	return ""
+}
+
+type StreamData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        uint32          `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Operation StreamOperation `protobuf:"varint,2,opt,name=operation,proto3,enum=msm_dp.StreamOperation" json:"operation,omitempty"`
+	Protocol  ProxyProtocol   `protobuf:"varint,3,opt,name=protocol,proto3,enum=msm_dp.ProxyProtocol" json:"protocol,omitempty"`
+	Endpoint  *Endpoint       `protobuf:"bytes,4,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	Enable    bool            `protobuf:"varint,5,opt,name=enable,proto3" json:"enable,omitempty"`
+	// payload codec, used to find keyframes in the RTP stream
+	Codec VideoCodec `protobuf:"varint,6,opt,name=codec,proto3,enum=msm_dp.VideoCodec" json:"codec,omitempty"`
+	// cache the packets since the last keyframe and replay them to new clients
+	CacheGop bool `protobuf:"varint,7,opt,name=cache_gop,json=cacheGop,proto3" json:"cache_gop,omitempty"`
+	// number of recent RTP packets kept to answer NACKs from clients, rounded up
+	// to a power of two and at most 8192, 0 disables
+	NackCacheDepth uint32 `protobuf:"varint,8,opt,name=nack_cache_depth,json=nackCacheDepth,proto3" json:"nack_cache_depth,omitempty"`
+	// present a stable SSRC with continuous sequence numbers and timestamps across source changes
+	RewriteSsrc bool `protobuf:"varint,9,opt,name=rewrite_ssrc,json=rewriteSsrc,proto3" json:"rewrite_ssrc,omitempty"`
+	// RTP clock rate of the stream, 90000 if not set
+	ClockRate uint32 `protobuf:"varint,10,opt,name=clock_rate,json=clockRate,proto3" json:"clock_rate,omitempty"`
+	// generate RTCP reports towards the clients and the source instead of forwarding the source's RTCP
+	TerminateRtcp bool `protobuf:"varint,11,opt,name=terminate_rtcp,json=terminateRtcp,proto3" json:"terminate_rtcp,omitempty"`
+	// additional sources sending the same RTP stream, merged by sequence number
+	RedundantSources []*Endpoint `protobuf:"bytes,12,rep,name=redundant_sources,json=redundantSources,proto3" json:"redundant_sources,omitempty"`
+	// sources to fail over to, in order, when the active source stops sending
+	BackupSources []*Endpoint `protobuf:"bytes,13,rep,name=backup_sources,json=backupSources,proto3" json:"backup_sources,omitempty"`
+	// time without packets after which the active source is considered dead
+	FailoverTimeoutMs uint32 `protobuf:"varint,14,opt,name=failover_timeout_ms,json=failoverTimeoutMs,proto3" json:"failover_timeout_ms,omitempty"`
+	// time without packets after which the stream is reported as stalled, 0 disables
+	IdleTimeoutMs uint32 `protobuf:"varint,15,opt,name=idle_timeout_ms,json=idleTimeoutMs,proto3" json:"idle_timeout_ms,omitempty"`
+	// time without packets after which the stream is removed, 0 disables
+	IdleRemoveMs uint32 `protobuf:"varint,16,opt,name=idle_remove_ms,json=idleRemoveMs,proto3" json:"idle_remove_ms,omitempty"`
+	// time without RTCP from a client after which it is reported as gone, 0 disables
+	ClientTimeoutMs uint32 `protobuf:"varint,17,opt,name=client_timeout_ms,json=clientTimeoutMs,proto3" json:"client_timeout_ms,omitempty"`
+	// disable clients that are gone or unreachable instead of only reporting them
+	DisableDeadClients bool `protobuf:"varint,18,opt,name=disable_dead_clients,json=disableDeadClients,proto3" json:"disable_dead_clients,omitempty"`
+	// learn the client's RTP and RTCP addresses from the packets it sends
+	// (symmetric RTP), UPD_EP and DEL_EP of the client must repeat the latch
+	// mode, SSRC and token of its ADD_EP
+	Latch LatchMode `protobuf:"varint,19,opt,name=latch,proto3,enum=msm_dp.LatchMode" json:"latch,omitempty"`
+	// SSRC the client sends with, for LATCH_SSRC
+	LatchSsrc uint32 `protobuf:"varint,20,opt,name=latch_ssrc,json=latchSsrc,proto3" json:"latch_ssrc,omitempty"`
+	// token the client's first packet starts with, for LATCH_TOKEN
+	LatchToken []byte `protobuf:"bytes,21,opt,name=latch_token,json=latchToken,proto3" json:"latch_token,omitempty"`
+	// time to wait for the client's first packet, 30s if not set
+	LatchTimeoutMs uint32 `protobuf:"varint,22,opt,name=latch_timeout_ms,json=latchTimeoutMs,proto3" json:"latch_timeout_ms,omitempty"`
+	// TTL of the packets sent to a multicast client, 1 if not set
+	MulticastTtl uint32 `protobuf:"varint,23,opt,name=multicast_ttl,json=multicastTtl,proto3" json:"multicast_ttl,omitempty"`
+	// interface to send to a multicast client or join a multicast source on, the default route if not set
+	MulticastInterface string `protobuf:"bytes,24,opt,name=multicast_interface,json=multicastInterface,proto3" json:"multicast_interface,omitempty"`
+	// source of a source-specific multicast group, when the stream's source is a multicast group
+	MulticastSource string `protobuf:"bytes,25,opt,name=multicast_source,json=multicastSource,proto3" json:"multicast_source,omitempty"`
+	// media carried by the stream, which selects its default DSCP
+	Media MediaType `protobuf:"varint,26,opt,name=media,proto3,enum=msm_dp.MediaType" json:"media,omitempty"`
+	// DSCP of the stream's egress on CREATE, or of the client's egress on ADD_EP,
+	// 0 included, the media type's default or the stream's DSCP if not set
+	Dscp *uint32 `protobuf:"varint,27,opt,name=dscp,proto3,oneof" json:"dscp,omitempty"`
+	// rate the packets sent to a client are paced at, on CREATE for all the
+	// stream's clients or on ADD_EP for the client, not paced if not set
+	PacingKbps uint32 `protobuf:"varint,28,opt,name=pacing_kbps,json=pacingKbps,proto3" json:"pacing_kbps,omitempty"`
+	// bandwidth above which packets sent to a client are dropped, on CREATE for
+	// all the stream's clients or on ADD_EP for the client, not capped if not set
+	MaxKbps uint32 `protobuf:"varint,29,opt,name=max_kbps,json=maxKbps,proto3" json:"max_kbps,omitempty"`
+}
+
+func (x *StreamData) Reset() {
+	*x = StreamData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamData) ProtoMessage() {}
+
+func (x *StreamData) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamData.ProtoReflect.Descriptor instead.
+func (*StreamData) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StreamData) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *StreamData) GetOperation() StreamOperation {
+	if x != nil {
+		return x.Operation
+	}
+	return StreamOperation_CREATE
+}
+
+func (x *StreamData) GetProtocol() ProxyProtocol {
+	if x != nil {
+		return x.Protocol
+	}
+	return ProxyProtocol_TCP
+}
+
+func (x *StreamData) GetEndpoint() *Endpoint {
+	if x != nil {
+		return x.Endpoint
+	}
+	return nil
+}
+
+func (x *StreamData) GetEnable() bool {
+	if x != nil {
+		return x.Enable
+	}
+	return false
+}
+
+func (x *StreamData) GetCodec() VideoCodec {
+	if x != nil {
+		return x.Codec
+	}
+	return VideoCodec_CODEC_NONE
+}
+
+func (x *StreamData) GetCacheGop() bool {
+	if x != nil {
+		return x.CacheGop
+	}
+	return false
+}
+
+func (x *StreamData) GetNackCacheDepth() uint32 {
+	if x != nil {
+		return x.NackCacheDepth
+	}
+	return 0
+}
+
+func (x *StreamData) GetRewriteSsrc() bool {
+	if x != nil {
+		return x.RewriteSsrc
+	}
+	return false
+}
+
+func (x *StreamData) GetClockRate() uint32 {
+	if x != nil {
+		return x.ClockRate
+	}
+	return 0
+}
+
+func (x *StreamData) GetTerminateRtcp() bool {
+	if x != nil {
+		return x.TerminateRtcp
+	}
+	return false
+}
+
+func (x *StreamData) GetRedundantSources() []*Endpoint {
+	if x != nil {
+		return x.RedundantSources
+	}
+	return nil
+}
+
+func (x *StreamData) GetBackupSources() []*Endpoint {
+	if x != nil {
+		return x.BackupSources
+	}
+	return nil
+}
+
+func (x *StreamData) GetFailoverTimeoutMs() uint32 {
+	if x != nil {
+		return x.FailoverTimeoutMs
+	}
+	return 0
+}
+
+func (x *StreamData) GetIdleTimeoutMs() uint32 {
+	if x != nil {
+		return x.IdleTimeoutMs
+	}
+	return 0
+}
+
+func (x *StreamData) GetIdleRemoveMs() uint32 {
+	if x != nil {
+		return x.IdleRemoveMs
+	}
+	return 0
+}
+
+func (x *StreamData) GetClientTimeoutMs() uint32 {
+	if x != nil {
+		return x.ClientTimeoutMs
+	}
+	return 0
+}
+
+func (x *StreamData) GetDisableDeadClients() bool {
+	if x != nil {
+		return x.DisableDeadClients
+	}
+	return false
+}
+
+func (x *StreamData) GetLatch() LatchMode {
+	if x != nil {
+		return x.Latch
+	}
+	return LatchMode_LATCH_NONE
+}
+
+func (x *StreamData) GetLatchSsrc() uint32 {
+	if x != nil {
+		return x.LatchSsrc
+	}
+	return 0
+}
+
+func (x *StreamData) GetLatchToken() []byte {
+	if x != nil {
+		return x.LatchToken
+	}
+	return nil
+}
+
+func (x *StreamData) GetLatchTimeoutMs() uint32 {
+	if x != nil {
+		return x.LatchTimeoutMs
+	}
+	return 0
+}
+
+func (x *StreamData) GetMulticastTtl() uint32 {
+	if x != nil {
+		return x.MulticastTtl
+	}
+	return 0
+}
+
+func (x *StreamData) GetMulticastInterface() string {
+	if x != nil {
+		return x.MulticastInterface
+	}
+	return ""
+}
+
+func (x *StreamData) GetMulticastSource() string {
+	if x != nil {
+		return x.MulticastSource
+	}
+	return ""
+}
+
+func (x *StreamData) GetMedia() MediaType {
+	if x != nil {
+		return x.Media
+	}
+	return MediaType_MEDIA_VIDEO
+}
+
+func (x *StreamData) GetDscp() uint32 {
+	if x != nil && x.Dscp != nil {
+		return *x.Dscp
+	}
+	return 0
+}
+
+func (x *StreamData) GetPacingKbps() uint32 {
+	if x != nil {
+		return x.PacingKbps
+	}
+	return 0
+}
+
+func (x *StreamData) GetMaxKbps() uint32 {
+	if x != nil {
+		return x.MaxKbps
+	}
+	return 0
+}
+
+type StreamResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// set when the request was applied, error_message says why it wasn't
+	// when it is known
+	Success      bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorMessage string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// generation of the stream registry after the request, which changes with
+	// every change and on restart, unless the streams were handed off
+	Generation uint64 `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`
+}
+
+func (x *StreamResult) Reset() {
+	*x = StreamResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamResult) ProtoMessage() {}
+
+func (x *StreamResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamResult.ProtoReflect.Descriptor instead.
+func (*StreamResult) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StreamResult) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *StreamResult) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type RegistryGenerationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RegistryGenerationRequest) Reset() {
+	*x = RegistryGenerationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegistryGenerationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryGenerationRequest) ProtoMessage() {}
+
+func (x *RegistryGenerationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryGenerationRequest.ProtoReflect.Descriptor instead.
+func (*RegistryGenerationRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{4}
+}
+
+type RegistryGeneration struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Generation uint64 `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+}
+
+func (x *RegistryGeneration) Reset() {
+	*x = RegistryGeneration{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegistryGeneration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryGeneration) ProtoMessage() {}
+
+func (x *RegistryGeneration) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryGeneration.ProtoReflect.Descriptor instead.
+func (*RegistryGeneration) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RegistryGeneration) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+// Registry is the snapshot of the streams and clients saved to disk, as the
+// requests that recreate them.
+type Registry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Generation uint64        `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	Requests   []*StreamData `protobuf:"bytes,2,rep,name=requests,proto3" json:"requests,omitempty"`
+	// forwarding state of the streams, only sent to a new msm-dp taking over
+	States []*StreamState `protobuf:"bytes,3,rep,name=states,proto3" json:"states,omitempty"`
+}
+
+func (x *Registry) Reset() {
+	*x = Registry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Registry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Registry) ProtoMessage() {}
+
+func (x *Registry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Registry.ProtoReflect.Descriptor instead.
+func (*Registry) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Registry) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *Registry) GetRequests() []*StreamData {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+func (x *Registry) GetStates() []*StreamState {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+// StreamState is the forwarding state of a stream that a new msm-dp taking
+// over continues from, so that SRTP indexes aren't reused and the clients see
+// the same SSRCs and sequence numbers.
+type StreamState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// index of the active source, the endpoint's followed by the backup ones
+	ActiveSource uint32 `protobuf:"varint,2,opt,name=active_source,json=activeSource,proto3" json:"active_source,omitempty"`
+	Ended        bool   `protobuf:"varint,3,opt,name=ended,proto3" json:"ended,omitempty"`
+	// SRTP state of the sources, in the same order
+	SourceSrtp []*SrtpState     `protobuf:"bytes,4,rep,name=source_srtp,json=sourceSrtp,proto3" json:"source_srtp,omitempty"`
+	Rewriter   *RewriterState   `protobuf:"bytes,5,opt,name=rewriter,proto3" json:"rewriter,omitempty"`
+	Translator *TranslatorState `protobuf:"bytes,6,opt,name=translator,proto3" json:"translator,omitempty"`
+	Clients    []*ClientState   `protobuf:"bytes,7,rep,name=clients,proto3" json:"clients,omitempty"`
+}
+
+func (x *StreamState) Reset() {
+	*x = StreamState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamState) ProtoMessage() {}
+
+func (x *StreamState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamState.ProtoReflect.Descriptor instead.
+func (*StreamState) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StreamState) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *StreamState) GetActiveSource() uint32 {
+	if x != nil {
+		return x.ActiveSource
+	}
+	return 0
+}
+
+func (x *StreamState) GetEnded() bool {
+	if x != nil {
+		return x.Ended
+	}
+	return false
+}
+
+func (x *StreamState) GetSourceSrtp() []*SrtpState {
+	if x != nil {
+		return x.SourceSrtp
+	}
+	return nil
+}
+
+func (x *StreamState) GetRewriter() *RewriterState {
+	if x != nil {
+		return x.Rewriter
+	}
+	return nil
+}
+
+func (x *StreamState) GetTranslator() *TranslatorState {
+	if x != nil {
+		return x.Translator
+	}
+	return nil
+}
+
+func (x *StreamState) GetClients() []*ClientState {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+type ClientState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// key of the client in its stream, its requested address and latch identity
+	Key     string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Enabled bool   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// RTP and RTCP addresses of a latched client, as ip:port
+	Address     string `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	RtcpAddress string `protobuf:"bytes,4,opt,name=rtcp_address,json=rtcpAddress,proto3" json:"rtcp_address,omitempty"`
+	Latched     bool   `protobuf:"varint,5,opt,name=latched,proto3" json:"latched,omitempty"`
+	RtpLatched  bool   `protobuf:"varint,6,opt,name=rtp_latched,json=rtpLatched,proto3" json:"rtp_latched,omitempty"`
+	RtcpLatched bool   `protobuf:"varint,7,opt,name=rtcp_latched,json=rtcpLatched,proto3" json:"rtcp_latched,omitempty"`
+	// packets and payload octets sent, reported in generated sender reports
+	Packets uint32     `protobuf:"varint,8,opt,name=packets,proto3" json:"packets,omitempty"`
+	Octets  uint32     `protobuf:"varint,9,opt,name=octets,proto3" json:"octets,omitempty"`
+	Srtp    *SrtpState `protobuf:"bytes,10,opt,name=srtp,proto3" json:"srtp,omitempty"`
+}
+
+func (x *ClientState) Reset() {
+	*x = ClientState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientState) ProtoMessage() {}
+
+func (x *ClientState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientState.ProtoReflect.Descriptor instead.
+func (*ClientState) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ClientState) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ClientState) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *ClientState) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ClientState) GetRtcpAddress() string {
+	if x != nil {
+		return x.RtcpAddress
+	}
+	return ""
+}
+
+func (x *ClientState) GetLatched() bool {
+	if x != nil {
+		return x.Latched
+	}
+	return false
+}
+
+func (x *ClientState) GetRtpLatched() bool {
+	if x != nil {
+		return x.RtpLatched
+	}
+	return false
+}
+
+func (x *ClientState) GetRtcpLatched() bool {
+	if x != nil {
+		return x.RtcpLatched
+	}
+	return false
+}
+
+func (x *ClientState) GetPackets() uint32 {
+	if x != nil {
+		return x.Packets
+	}
+	return 0
+}
+
+func (x *ClientState) GetOctets() uint32 {
+	if x != nil {
+		return x.Octets
+	}
+	return 0
+}
+
+func (x *ClientState) GetSrtp() *SrtpState {
+	if x != nil {
+		return x.Srtp
+	}
+	return nil
+}
+
+// SrtpState is the state of the SSRCs an endpoint sends and receives SRTP with.
+type SrtpState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sent     []*SrtpSsrcState `protobuf:"bytes,1,rep,name=sent,proto3" json:"sent,omitempty"`
+	Received []*SrtpSsrcState `protobuf:"bytes,2,rep,name=received,proto3" json:"received,omitempty"`
+}
+
+func (x *SrtpState) Reset() {
+	*x = SrtpState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SrtpState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SrtpState) ProtoMessage() {}
+
+func (x *SrtpState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SrtpState.ProtoReflect.Descriptor instead.
+func (*SrtpState) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SrtpState) GetSent() []*SrtpSsrcState {
+	if x != nil {
+		return x.Sent
+	}
+	return nil
+}
+
+func (x *SrtpState) GetReceived() []*SrtpSsrcState {
+	if x != nil {
+		return x.Received
+	}
+	return nil
+}
+
+type SrtpSsrcState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ssrc       uint32        `protobuf:"varint,1,opt,name=ssrc,proto3" json:"ssrc,omitempty"`
+	Started    bool          `protobuf:"varint,2,opt,name=started,proto3" json:"started,omitempty"`
+	Roc        uint32        `protobuf:"varint,3,opt,name=roc,proto3" json:"roc,omitempty"`
+	Seq        uint32        `protobuf:"varint,4,opt,name=seq,proto3" json:"seq,omitempty"`
+	Replay     *ReplayWindow `protobuf:"bytes,5,opt,name=replay,proto3" json:"replay,omitempty"`
+	RtcpIndex  uint32        `protobuf:"varint,6,opt,name=rtcp_index,json=rtcpIndex,proto3" json:"rtcp_index,omitempty"`
+	RtcpReplay *ReplayWindow `protobuf:"bytes,7,opt,name=rtcp_replay,json=rtcpReplay,proto3" json:"rtcp_replay,omitempty"`
+}
+
+func (x *SrtpSsrcState) Reset() {
+	*x = SrtpSsrcState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SrtpSsrcState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SrtpSsrcState) ProtoMessage() {}
+
+func (x *SrtpSsrcState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SrtpSsrcState.ProtoReflect.Descriptor instead.
+func (*SrtpSsrcState) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SrtpSsrcState) GetSsrc() uint32 {
+	if x != nil {
+		return x.Ssrc
+	}
+	return 0
+}
+
+func (x *SrtpSsrcState) GetStarted() bool {
+	if x != nil {
+		return x.Started
+	}
+	return false
+}
+
+func (x *SrtpSsrcState) GetRoc() uint32 {
+	if x != nil {
+		return x.Roc
+	}
+	return 0
+}
+
+func (x *SrtpSsrcState) GetSeq() uint32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *SrtpSsrcState) GetReplay() *ReplayWindow {
+	if x != nil {
+		return x.Replay
+	}
+	return nil
+}
+
+func (x *SrtpSsrcState) GetRtcpIndex() uint32 {
+	if x != nil {
+		return x.RtcpIndex
+	}
+	return 0
+}
+
+func (x *SrtpSsrcState) GetRtcpReplay() *ReplayWindow {
+	if x != nil {
+		return x.RtcpReplay
+	}
+	return nil
+}
+
+type ReplayWindow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Started bool   `protobuf:"varint,1,opt,name=started,proto3" json:"started,omitempty"`
+	Highest uint64 `protobuf:"varint,2,opt,name=highest,proto3" json:"highest,omitempty"`
+	Bitmap  uint64 `protobuf:"varint,3,opt,name=bitmap,proto3" json:"bitmap,omitempty"`
+}
+
+func (x *ReplayWindow) Reset() {
+	*x = ReplayWindow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplayWindow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayWindow) ProtoMessage() {}
+
+func (x *ReplayWindow) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayWindow.ProtoReflect.Descriptor instead.
+func (*ReplayWindow) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReplayWindow) GetStarted() bool {
+	if x != nil {
+		return x.Started
+	}
+	return false
+}
+
+func (x *ReplayWindow) GetHighest() uint64 {
+	if x != nil {
+		return x.Highest
+	}
+	return 0
+}
+
+func (x *ReplayWindow) GetBitmap() uint64 {
+	if x != nil {
+		return x.Bitmap
+	}
+	return 0
+}
+
+type RewriterState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ssrc      uint32 `protobuf:"varint,1,opt,name=ssrc,proto3" json:"ssrc,omitempty"`
+	Started   bool   `protobuf:"varint,2,opt,name=started,proto3" json:"started,omitempty"`
+	Switching bool   `protobuf:"varint,3,opt,name=switching,proto3" json:"switching,omitempty"`
+	InSsrc    uint32 `protobuf:"varint,4,opt,name=in_ssrc,json=inSsrc,proto3" json:"in_ssrc,omitempty"`
+	SeqOffset uint32 `protobuf:"varint,5,opt,name=seq_offset,json=seqOffset,proto3" json:"seq_offset,omitempty"`
+	TsOffset  uint32 `protobuf:"varint,6,opt,name=ts_offset,json=tsOffset,proto3" json:"ts_offset,omitempty"`
+	LastSeq   uint32 `protobuf:"varint,7,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"`
+	LastTs    uint32 `protobuf:"varint,8,opt,name=last_ts,json=lastTs,proto3" json:"last_ts,omitempty"`
+	// times are in Unix nanoseconds, 0 if not set
+	LastTime int64 `protobuf:"varint,9,opt,name=last_time,json=lastTime,proto3" json:"last_time,omitempty"`
+}
+
+func (x *RewriterState) Reset() {
+	*x = RewriterState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RewriterState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewriterState) ProtoMessage() {}
+
+func (x *RewriterState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewriterState.ProtoReflect.Descriptor instead.
+func (*RewriterState) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RewriterState) GetSsrc() uint32 {
+	if x != nil {
+		return x.Ssrc
+	}
+	return 0
+}
+
+func (x *RewriterState) GetStarted() bool {
+	if x != nil {
+		return x.Started
+	}
+	return false
+}
+
+func (x *RewriterState) GetSwitching() bool {
+	if x != nil {
+		return x.Switching
+	}
+	return false
+}
+
+func (x *RewriterState) GetInSsrc() uint32 {
+	if x != nil {
+		return x.InSsrc
+	}
+	return 0
+}
+
+func (x *RewriterState) GetSeqOffset() uint32 {
+	if x != nil {
+		return x.SeqOffset
+	}
+	return 0
+}
+
+func (x *RewriterState) GetTsOffset() uint32 {
+	if x != nil {
+		return x.TsOffset
+	}
+	return 0
+}
+
+func (x *RewriterState) GetLastSeq() uint32 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
+func (x *RewriterState) GetLastTs() uint32 {
+	if x != nil {
+		return x.LastTs
+	}
+	return 0
+}
+
+func (x *RewriterState) GetLastTime() int64 {
+	if x != nil {
+		return x.LastTime
+	}
+	return 0
+}
+
+type TranslatorState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ssrc       uint32         `protobuf:"varint,1,opt,name=ssrc,proto3" json:"ssrc,omitempty"`
+	SourceSsrc uint32         `protobuf:"varint,2,opt,name=source_ssrc,json=sourceSsrc,proto3" json:"source_ssrc,omitempty"`
+	OutSsrc    uint32         `protobuf:"varint,3,opt,name=out_ssrc,json=outSsrc,proto3" json:"out_ssrc,omitempty"`
+	SrNtp      uint64         `protobuf:"varint,4,opt,name=sr_ntp,json=srNtp,proto3" json:"sr_ntp,omitempty"`
+	SrRtp      uint32         `protobuf:"varint,5,opt,name=sr_rtp,json=srRtp,proto3" json:"sr_rtp,omitempty"`
+	SrTime     int64          `protobuf:"varint,6,opt,name=sr_time,json=srTime,proto3" json:"sr_time,omitempty"`
+	LastRtp    uint32         `protobuf:"varint,7,opt,name=last_rtp,json=lastRtp,proto3" json:"last_rtp,omitempty"`
+	LastTime   int64          `protobuf:"varint,8,opt,name=last_time,json=lastTime,proto3" json:"last_time,omitempty"`
+	Source     *ReceiverState `protobuf:"bytes,9,opt,name=source,proto3" json:"source,omitempty"`
+}
+
+func (x *TranslatorState) Reset() {
+	*x = TranslatorState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TranslatorState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranslatorState) ProtoMessage() {}
+
+func (x *TranslatorState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranslatorState.ProtoReflect.Descriptor instead.
+func (*TranslatorState) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TranslatorState) GetSsrc() uint32 {
+	if x != nil {
+		return x.Ssrc
+	}
+	return 0
+}
+
+func (x *TranslatorState) GetSourceSsrc() uint32 {
+	if x != nil {
+		return x.SourceSsrc
+	}
+	return 0
+}
+
+func (x *TranslatorState) GetOutSsrc() uint32 {
+	if x != nil {
+		return x.OutSsrc
+	}
+	return 0
+}
+
+func (x *TranslatorState) GetSrNtp() uint64 {
+	if x != nil {
+		return x.SrNtp
+	}
+	return 0
+}
+
+func (x *TranslatorState) GetSrRtp() uint32 {
+	if x != nil {
+		return x.SrRtp
+	}
+	return 0
+}
+
+func (x *TranslatorState) GetSrTime() int64 {
+	if x != nil {
+		return x.SrTime
+	}
+	return 0
+}
+
+func (x *TranslatorState) GetLastRtp() uint32 {
+	if x != nil {
+		return x.LastRtp
+	}
+	return 0
+}
+
+func (x *TranslatorState) GetLastTime() int64 {
+	if x != nil {
+		return x.LastTime
+	}
+	return 0
+}
+
+func (x *TranslatorState) GetSource() *ReceiverState {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+type ReceiverState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Started       bool    `protobuf:"varint,1,opt,name=started,proto3" json:"started,omitempty"`
+	BaseSeq       uint32  `protobuf:"varint,2,opt,name=base_seq,json=baseSeq,proto3" json:"base_seq,omitempty"`
+	MaxSeq        uint32  `protobuf:"varint,3,opt,name=max_seq,json=maxSeq,proto3" json:"max_seq,omitempty"`
+	BadSeq        uint32  `protobuf:"varint,4,opt,name=bad_seq,json=badSeq,proto3" json:"bad_seq,omitempty"`
+	Cycles        uint32  `protobuf:"varint,5,opt,name=cycles,proto3" json:"cycles,omitempty"`
+	Received      uint32  `protobuf:"varint,6,opt,name=received,proto3" json:"received,omitempty"`
+	ExpectedPrior uint32  `protobuf:"varint,7,opt,name=expected_prior,json=expectedPrior,proto3" json:"expected_prior,omitempty"`
+	ReceivedPrior uint32  `protobuf:"varint,8,opt,name=received_prior,json=receivedPrior,proto3" json:"received_prior,omitempty"`
+	Transit       int64   `protobuf:"varint,9,opt,name=transit,proto3" json:"transit,omitempty"`
+	Jitter        float64 `protobuf:"fixed64,10,opt,name=jitter,proto3" json:"jitter,omitempty"`
+	LastSr        uint32  `protobuf:"varint,11,opt,name=last_sr,json=lastSr,proto3" json:"last_sr,omitempty"`
+	LastSrTime    int64   `protobuf:"varint,12,opt,name=last_sr_time,json=lastSrTime,proto3" json:"last_sr_time,omitempty"`
+}
+
+func (x *ReceiverState) Reset() {
+	*x = ReceiverState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReceiverState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceiverState) ProtoMessage() {}
+
+func (x *ReceiverState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceiverState.ProtoReflect.Descriptor instead.
+func (*ReceiverState) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReceiverState) GetStarted() bool {
+	if x != nil {
+		return x.Started
+	}
+	return false
+}
+
+func (x *ReceiverState) GetBaseSeq() uint32 {
+	if x != nil {
+		return x.BaseSeq
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetMaxSeq() uint32 {
+	if x != nil {
+		return x.MaxSeq
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetBadSeq() uint32 {
+	if x != nil {
+		return x.BadSeq
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetCycles() uint32 {
+	if x != nil {
+		return x.Cycles
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetReceived() uint32 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetExpectedPrior() uint32 {
+	if x != nil {
+		return x.ExpectedPrior
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetReceivedPrior() uint32 {
+	if x != nil {
+		return x.ReceivedPrior
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetTransit() int64 {
+	if x != nil {
+		return x.Transit
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetJitter() float64 {
+	if x != nil {
+		return x.Jitter
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetLastSr() uint32 {
+	if x != nil {
+		return x.LastSr
+	}
+	return 0
+}
+
+func (x *ReceiverState) GetLastSrTime() int64 {
+	if x != nil {
+		return x.LastSrTime
+	}
+	return 0
+}
+
+type StreamStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *StreamStatsRequest) Reset() {
+	*x = StreamStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamStatsRequest) ProtoMessage() {}
+
+func (x *StreamStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamStatsRequest.ProtoReflect.Descriptor instead.
+func (*StreamStatsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StreamStatsRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type StreamStats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id        uint32          `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Operation StreamOperation `protobuf:"varint,2,opt,name=operation,proto3,enum=msm_dp.StreamOperation" json:"operation,omitempty"`
-	Protocol  ProxyProtocol   `protobuf:"varint,3,opt,name=protocol,proto3,enum=msm_dp.ProxyProtocol" json:"protocol,omitempty"`
-	Endpoint  *Endpoint       `protobuf:"bytes,4,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
-	Enable    bool            `protobuf:"varint,5,opt,name=enable,proto3" json:"enable,omitempty"`
+	Id       uint32            `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Counters map[string]uint64 `protobuf:"bytes,2,rep,name=counters,proto3" json:"counters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 }
 
-func (x *StreamData) Reset() {
-	*x = StreamData{}
+func (x *StreamStats) Reset() {
+	*x = StreamStats{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[1]
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StreamData) String() string {
+func (x *StreamStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamData) ProtoMessage() {}
+func (*StreamStats) ProtoMessage() {}
 
-func (x *StreamData) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[1]
+func (x *StreamStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -374,72 +2034,91 @@ func (x *StreamData) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamData.ProtoReflect.Descriptor instead.
-func (*StreamData) Descriptor() ([]byte, []int) {
-	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use StreamStats.ProtoReflect.Descriptor instead.
+func (*StreamStats) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *StreamData) GetId() uint32 {
+func (x *StreamStats) GetId() uint32 {
 	if x != nil {
 		return x.Id
 	}
 	return 0
 }
 
-func (x *StreamData) GetOperation() StreamOperation {
+func (x *StreamStats) GetCounters() map[string]uint64 {
 	if x != nil {
-		return x.Operation
+		return x.Counters
 	}
-	return StreamOperation_CREATE
+	return nil
 }
 
-func (x *StreamData) GetProtocol() ProxyProtocol {
-	if x != nil {
-		return x.Protocol
-	}
-	return ProxyProtocol_TCP
+type StreamEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 }
 
-func (x *StreamData) GetEndpoint() *Endpoint {
-	if x != nil {
-		return x.Endpoint
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return nil
 }
 
-func (x *StreamData) GetEnable() bool {
-	if x != nil {
-		return x.Enable
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return false
+	return mi.MessageOf(x)
 }
 
-type StreamResult struct {
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{17}
+}
+
+type StreamEvent struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Success      bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	ErrorMessage string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Id       uint32          `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type     StreamEventType `protobuf:"varint,2,opt,name=type,proto3,enum=msm_dp.StreamEventType" json:"type,omitempty"`
+	Endpoint *Endpoint       `protobuf:"bytes,3,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	Message  string          `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (x *StreamResult) Reset() {
-	*x = StreamResult{}
+func (x *StreamEvent) Reset() {
+	*x = StreamEvent{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[2]
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StreamResult) String() string {
+func (x *StreamEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamResult) ProtoMessage() {}
+func (*StreamEvent) ProtoMessage() {}
 
-func (x *StreamResult) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[2]
+func (x *StreamEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -450,21 +2129,35 @@ func (x *StreamResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamResult.ProtoReflect.Descriptor instead.
-func (*StreamResult) Descriptor() ([]byte, []int) {
-	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use StreamEvent.ProtoReflect.Descriptor instead.
+func (*StreamEvent) Descriptor() ([]byte, []int) {
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *StreamResult) GetSuccess() bool {
+func (x *StreamEvent) GetId() uint32 {
 	if x != nil {
-		return x.Success
+		return x.Id
 	}
-	return false
+	return 0
 }
 
-func (x *StreamResult) GetErrorMessage() string {
+func (x *StreamEvent) GetType() StreamEventType {
 	if x != nil {
-		return x.ErrorMessage
+		return x.Type
+	}
+	return StreamEventType_SOURCE_FAILOVER
+}
+
+func (x *StreamEvent) GetEndpoint() *Endpoint {
+	if x != nil {
+		return x.Endpoint
+	}
+	return nil
+}
+
+func (x *StreamEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
@@ -481,7 +2174,7 @@ type HealthCheckRequest struct {
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[3]
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -494,7 +2187,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[3]
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -507,7 +2200,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{3}
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *HealthCheckRequest) GetService() string {
@@ -529,7 +2222,7 @@ type HealthCheckResponse struct {
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[4]
+		mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -542,7 +2235,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[4]
+	mi := &file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -555,7 +2248,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{4}
+	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_ServingStatus {
@@ -570,82 +2263,354 @@ var File_api_v1alpha1_msm_dp_msm_dp_proto protoreflect.FileDescriptor
 var file_api_v1alpha1_msm_dp_msm_dp_proto_rawDesc = []byte{
 	0x0a, 0x20, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2f, 0x6d,
 	0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2f, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x06, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x22, 0x65, 0x0a, 0x08, 0x45, 0x6e,
-	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x71, 0x75,
-	0x69, 0x63, 0x5f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x0a, 0x71, 0x75, 0x69, 0x63, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x65,
-	0x6e, 0x63, 0x61, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x65, 0x6e, 0x63, 0x61,
-	0x70, 0x22, 0xcc, 0x01, 0x0a, 0x0a, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x61, 0x74, 0x61,
+	0x74, 0x6f, 0x12, 0x06, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x22, 0x8d, 0x01, 0x0a, 0x08, 0x45,
+	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x71,
+	0x75, 0x69, 0x63, 0x5f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0a, 0x71, 0x75, 0x69, 0x63, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x14, 0x0a, 0x05,
+	0x65, 0x6e, 0x63, 0x61, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x65, 0x6e, 0x63,
+	0x61, 0x70, 0x12, 0x26, 0x0a, 0x04, 0x73, 0x72, 0x74, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x72, 0x74, 0x70, 0x43, 0x72,
+	0x79, 0x70, 0x74, 0x6f, 0x52, 0x04, 0x73, 0x72, 0x74, 0x70, 0x22, 0x9f, 0x01, 0x0a, 0x0a, 0x53,
+	0x72, 0x74, 0x70, 0x43, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x75, 0x69,
+	0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x75, 0x69, 0x74, 0x65, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x2c,
+	0x0a, 0x12, 0x69, 0x6e, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x61,
+	0x72, 0x61, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x69, 0x6e, 0x62, 0x6f,
+	0x75, 0x6e, 0x64, 0x4b, 0x65, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x2e, 0x0a, 0x13,
+	0x6f, 0x75, 0x74, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x6f, 0x75, 0x74, 0x62, 0x6f,
+	0x75, 0x6e, 0x64, 0x4b, 0x65, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x95, 0x09, 0x0a,
+	0x0a, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x61, 0x74, 0x61, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x35, 0x0a, 0x09, 0x6f,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17,
+	0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x31, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x50, 0x72,
+	0x6f, 0x78, 0x79, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x2c, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70,
+	0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x28, 0x0a, 0x05, 0x63,
+	0x6f, 0x64, 0x65, 0x63, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x6d, 0x73, 0x6d,
+	0x5f, 0x64, 0x70, 0x2e, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x43, 0x6f, 0x64, 0x65, 0x63, 0x52, 0x05,
+	0x63, 0x6f, 0x64, 0x65, 0x63, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x67,
+	0x6f, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x63, 0x61, 0x63, 0x68, 0x65, 0x47,
+	0x6f, 0x70, 0x12, 0x28, 0x0a, 0x10, 0x6e, 0x61, 0x63, 0x6b, 0x5f, 0x63, 0x61, 0x63, 0x68, 0x65,
+	0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6e, 0x61,
+	0x63, 0x6b, 0x43, 0x61, 0x63, 0x68, 0x65, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x21, 0x0a, 0x0c,
+	0x72, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x73, 0x73, 0x72, 0x63, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0b, 0x72, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x53, 0x73, 0x72, 0x63, 0x12,
+	0x1d, 0x0a, 0x0a, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x09, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x61, 0x74, 0x65, 0x12, 0x25,
+	0x0a, 0x0e, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x5f, 0x72, 0x74, 0x63, 0x70,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74,
+	0x65, 0x52, 0x74, 0x63, 0x70, 0x12, 0x3d, 0x0a, 0x11, 0x72, 0x65, 0x64, 0x75, 0x6e, 0x64, 0x61,
+	0x6e, 0x74, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x52, 0x10, 0x72, 0x65, 0x64, 0x75, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x53, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x0e, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x5f, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d,
+	0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x0d,
+	0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x2e, 0x0a,
+	0x13, 0x66, 0x61, 0x69, 0x6c, 0x6f, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x5f, 0x6d, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x66, 0x61, 0x69, 0x6c,
+	0x6f, 0x76, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x26, 0x0a,
+	0x0f, 0x69, 0x64, 0x6c, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x69, 0x64, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x69, 0x64, 0x6c, 0x65, 0x5f, 0x72, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x5f, 0x6d, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x69,
+	0x64, 0x6c, 0x65, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x4d, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x64, 0x69, 0x73, 0x61, 0x62,
+	0x6c, 0x65, 0x5f, 0x64, 0x65, 0x61, 0x64, 0x5f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x18,
+	0x12, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x65,
+	0x61, 0x64, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x27, 0x0a, 0x05, 0x6c, 0x61, 0x74,
+	0x63, 0x68, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64,
+	0x70, 0x2e, 0x4c, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x6c, 0x61, 0x74,
+	0x63, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x73, 0x73, 0x72, 0x63,
+	0x18, 0x14, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x6c, 0x61, 0x74, 0x63, 0x68, 0x53, 0x73, 0x72,
+	0x63, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x18, 0x15, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x6c, 0x61, 0x74, 0x63, 0x68, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x12, 0x28, 0x0a, 0x10, 0x6c, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6c, 0x61,
+	0x74, 0x63, 0x68, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x23, 0x0a, 0x0d,
+	0x6d, 0x75, 0x6c, 0x74, 0x69, 0x63, 0x61, 0x73, 0x74, 0x5f, 0x74, 0x74, 0x6c, 0x18, 0x17, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0c, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x63, 0x61, 0x73, 0x74, 0x54, 0x74,
+	0x6c, 0x12, 0x2f, 0x0a, 0x13, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x63, 0x61, 0x73, 0x74, 0x5f, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x18, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12,
+	0x6d, 0x75, 0x6c, 0x74, 0x69, 0x63, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61,
+	0x63, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x63, 0x61, 0x73, 0x74, 0x5f,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x19, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6d, 0x75,
+	0x6c, 0x74, 0x69, 0x63, 0x61, 0x73, 0x74, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x27, 0x0a,
+	0x05, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x6d,
+	0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x54, 0x79, 0x70, 0x65, 0x52,
+	0x05, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x12, 0x17, 0x0a, 0x04, 0x64, 0x73, 0x63, 0x70, 0x18, 0x1b,
+	0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52, 0x04, 0x64, 0x73, 0x63, 0x70, 0x88, 0x01, 0x01, 0x12,
+	0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x63, 0x69, 0x6e, 0x67, 0x5f, 0x6b, 0x62, 0x70, 0x73, 0x18, 0x1c,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x70, 0x61, 0x63, 0x69, 0x6e, 0x67, 0x4b, 0x62, 0x70, 0x73,
+	0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x6b, 0x62, 0x70, 0x73, 0x18, 0x1d, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x4b, 0x62, 0x70, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f,
+	0x64, 0x73, 0x63, 0x70, 0x22, 0x6d, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x23,
+	0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x22, 0x1b, 0x0a, 0x19, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x47,
+	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x22, 0x34, 0x0a, 0x12, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x47, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x87, 0x01, 0x0a, 0x08, 0x52, 0x65, 0x67, 0x69, 0x73,
+	0x74, 0x72, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x61, 0x74, 0x61, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x73, 0x12, 0x2b, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x65, 0x73,
+	0x22, 0xa7, 0x02, 0x0a, 0x0b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x23, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x53,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x12, 0x32, 0x0a, 0x0b, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x73, 0x72, 0x74, 0x70, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x72, 0x74, 0x70, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x52, 0x0a, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x53, 0x72, 0x74, 0x70, 0x12,
+	0x31, 0x0a, 0x08, 0x72, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x52, 0x65, 0x77, 0x72, 0x69,
+	0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x08, 0x72, 0x65, 0x77, 0x72, 0x69, 0x74,
+	0x65, 0x72, 0x12, 0x37, 0x0a, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x6f, 0x72,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x2d, 0x0a, 0x07, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x6d,
+	0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x07, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x22, 0xad, 0x02, 0x0a, 0x0b, 0x43,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x18, 0x0a, 0x07,
+	0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65,
+	0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x21, 0x0a, 0x0c, 0x72, 0x74, 0x63, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x74, 0x63, 0x70, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6c, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x12, 0x1f, 0x0a,
+	0x0b, 0x72, 0x74, 0x70, 0x5f, 0x6c, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0a, 0x72, 0x74, 0x70, 0x4c, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x12, 0x21,
+	0x0a, 0x0c, 0x72, 0x74, 0x63, 0x70, 0x5f, 0x6c, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x72, 0x74, 0x63, 0x70, 0x4c, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6f,
+	0x63, 0x74, 0x65, 0x74, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6f, 0x63, 0x74,
+	0x65, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x04, 0x73, 0x72, 0x74, 0x70, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x11, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x72, 0x74, 0x70, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x52, 0x04, 0x73, 0x72, 0x74, 0x70, 0x22, 0x69, 0x0a, 0x09, 0x53, 0x72,
+	0x74, 0x70, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x04, 0x73, 0x65, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53,
+	0x72, 0x74, 0x70, 0x53, 0x73, 0x72, 0x63, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x04, 0x73, 0x65,
+	0x6e, 0x74, 0x12, 0x31, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x72,
+	0x74, 0x70, 0x53, 0x73, 0x72, 0x63, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x08, 0x72, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x64, 0x22, 0xe5, 0x01, 0x0a, 0x0d, 0x53, 0x72, 0x74, 0x70, 0x53, 0x73,
+	0x72, 0x63, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x73, 0x72, 0x63, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x73, 0x72, 0x63, 0x12, 0x18, 0x0a, 0x07, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x6f, 0x63, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x03, 0x72, 0x6f, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x73, 0x65, 0x71, 0x12, 0x2c, 0x0a, 0x06, 0x72, 0x65, 0x70,
+	0x6c, 0x61, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x73, 0x6d, 0x5f,
+	0x64, 0x70, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x52,
+	0x06, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x74, 0x63, 0x70, 0x5f,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x72, 0x74, 0x63,
+	0x70, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x35, 0x0a, 0x0b, 0x72, 0x74, 0x63, 0x70, 0x5f, 0x72,
+	0x65, 0x70, 0x6c, 0x61, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x73,
+	0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x57, 0x69, 0x6e, 0x64, 0x6f,
+	0x77, 0x52, 0x0a, 0x72, 0x74, 0x63, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x22, 0x5a, 0x0a,
+	0x0c, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x68, 0x69, 0x67, 0x68, 0x65,
+	0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x68, 0x69, 0x67, 0x68, 0x65, 0x73,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x69, 0x74, 0x6d, 0x61, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x62, 0x69, 0x74, 0x6d, 0x61, 0x70, 0x22, 0x81, 0x02, 0x0a, 0x0d, 0x52, 0x65,
+	0x77, 0x72, 0x69, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x73, 0x72, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x73, 0x72, 0x63, 0x12,
+	0x18, 0x0a, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x77, 0x69,
+	0x74, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x73, 0x77,
+	0x69, 0x74, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x6e, 0x5f, 0x73, 0x73,
+	0x72, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x69, 0x6e, 0x53, 0x73, 0x72, 0x63,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x71, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x73, 0x65, 0x71, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12,
+	0x1b, 0x0a, 0x09, 0x74, 0x73, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x08, 0x74, 0x73, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x19, 0x0a, 0x08,
+	0x6c, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x65, 0x71, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x71, 0x12, 0x17, 0x0a, 0x07, 0x6c, 0x61, 0x73, 0x74, 0x5f,
+	0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6c, 0x61, 0x73, 0x74, 0x54, 0x73,
+	0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x8f, 0x02,
+	0x0a, 0x0f, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x73, 0x72, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x73, 0x73, 0x72, 0x63, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f,
+	0x73, 0x73, 0x72, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x53, 0x73, 0x72, 0x63, 0x12, 0x19, 0x0a, 0x08, 0x6f, 0x75, 0x74, 0x5f, 0x73, 0x73,
+	0x72, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6f, 0x75, 0x74, 0x53, 0x73, 0x72,
+	0x63, 0x12, 0x15, 0x0a, 0x06, 0x73, 0x72, 0x5f, 0x6e, 0x74, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x05, 0x73, 0x72, 0x4e, 0x74, 0x70, 0x12, 0x15, 0x0a, 0x06, 0x73, 0x72, 0x5f, 0x72,
+	0x74, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x73, 0x72, 0x52, 0x74, 0x70, 0x12,
+	0x17, 0x0a, 0x07, 0x73, 0x72, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x73, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x72, 0x74, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6c, 0x61, 0x73, 0x74,
+	0x52, 0x74, 0x70, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x2d, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22,
+	0xe5, 0x02, 0x0a, 0x0d, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x62,
+	0x61, 0x73, 0x65, 0x5f, 0x73, 0x65, 0x71, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x62,
+	0x61, 0x73, 0x65, 0x53, 0x65, 0x71, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x73, 0x65,
+	0x71, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x53, 0x65, 0x71, 0x12,
+	0x17, 0x0a, 0x07, 0x62, 0x61, 0x64, 0x5f, 0x73, 0x65, 0x71, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x06, 0x62, 0x61, 0x64, 0x53, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x79, 0x63, 0x6c,
+	0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x08, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x25, 0x0a, 0x0e,
+	0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x50, 0x72,
+	0x69, 0x6f, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x5f,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x72, 0x65, 0x63,
+	0x65, 0x69, 0x76, 0x65, 0x64, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x69, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x12, 0x17, 0x0a, 0x07,
+	0x6c, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6c,
+	0x61, 0x73, 0x74, 0x53, 0x72, 0x12, 0x20, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x72,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6c, 0x61, 0x73,
+	0x74, 0x53, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x24, 0x0a, 0x12, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x22, 0x99, 0x01,
+	0x0a, 0x0b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x3d, 0x0a,
+	0x08, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x21, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x08, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x1a, 0x3b, 0x0a, 0x0d,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x15, 0x0a, 0x13, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x22, 0x92, 0x01, 0x0a, 0x0b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74,
 	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64,
-	0x12, 0x35, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6f, 0x70,
-	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x31, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x63, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x6d, 0x73, 0x6d, 0x5f,
-	0x64, 0x70, 0x2e, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
-	0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x2c, 0x0a, 0x08, 0x65, 0x6e,
-	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6d,
-	0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x08,
-	0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x6e, 0x61, 0x62,
-	0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65,
-	0x22, 0x4d, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x72,
-	0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22,
-	0x2e, 0x0a, 0x12, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x22,
-	0xa9, 0x01, 0x0a, 0x13, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70,
+	0x12, 0x2b, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17,
+	0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x2c, 0x0a,
+	0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x52, 0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x2e, 0x0a, 0x12, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x22, 0xa9, 0x01, 0x0a, 0x13, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29, 0x2e,
+	0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x6e, 0x67, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x22, 0x4f, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0b,
+	0x0a, 0x07, 0x53, 0x45, 0x52, 0x56, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x4e,
+	0x4f, 0x54, 0x5f, 0x53, 0x45, 0x52, 0x56, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f,
+	0x53, 0x45, 0x52, 0x56, 0x49, 0x43, 0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10,
+	0x03, 0x2a, 0x59, 0x0a, 0x0f, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4f, 0x70, 0x65, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x10, 0x00,
+	0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06,
+	0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x44, 0x44, 0x5f,
+	0x45, 0x50, 0x10, 0x03, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c, 0x5f, 0x45, 0x50, 0x10, 0x04,
+	0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50, 0x44, 0x5f, 0x45, 0x50, 0x10, 0x05, 0x2a, 0x34, 0x0a, 0x0d,
+	0x50, 0x72, 0x6f, 0x78, 0x79, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x07, 0x0a,
+	0x03, 0x54, 0x43, 0x50, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x55, 0x44, 0x50, 0x10, 0x01, 0x12,
+	0x08, 0x0a, 0x04, 0x51, 0x55, 0x49, 0x43, 0x10, 0x02, 0x12, 0x07, 0x0a, 0x03, 0x52, 0x54, 0x50,
+	0x10, 0x03, 0x2a, 0x91, 0x01, 0x0a, 0x05, 0x45, 0x6e, 0x63, 0x61, 0x70, 0x12, 0x0a, 0x0a, 0x06,
+	0x54, 0x43, 0x50, 0x5f, 0x49, 0x50, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x44, 0x50, 0x5f,
+	0x49, 0x50, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x51, 0x55, 0x49, 0x43, 0x5f, 0x49, 0x50, 0x10,
+	0x02, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x54, 0x50, 0x5f, 0x55, 0x44, 0x50, 0x10, 0x03, 0x12, 0x0f,
+	0x0a, 0x0b, 0x52, 0x54, 0x50, 0x5f, 0x55, 0x44, 0x50, 0x5f, 0x4d, 0x55, 0x58, 0x10, 0x04, 0x12,
+	0x0b, 0x0a, 0x07, 0x52, 0x54, 0x50, 0x5f, 0x54, 0x43, 0x50, 0x10, 0x05, 0x12, 0x0f, 0x0a, 0x0b,
+	0x52, 0x54, 0x50, 0x5f, 0x54, 0x43, 0x50, 0x5f, 0x4d, 0x55, 0x58, 0x10, 0x06, 0x12, 0x13, 0x0a,
+	0x0f, 0x52, 0x54, 0x50, 0x5f, 0x51, 0x55, 0x49, 0x43, 0x5f, 0x53, 0x54, 0x52, 0x45, 0x41, 0x4d,
+	0x10, 0x07, 0x12, 0x12, 0x0a, 0x0e, 0x52, 0x54, 0x50, 0x5f, 0x51, 0x55, 0x49, 0x43, 0x5f, 0x44,
+	0x47, 0x52, 0x41, 0x4d, 0x10, 0x08, 0x2a, 0x51, 0x0a, 0x09, 0x4c, 0x61, 0x74, 0x63, 0x68, 0x4d,
+	0x6f, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x0a, 0x4c, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x4e, 0x4f, 0x4e,
+	0x45, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x4c, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x53, 0x4f, 0x55,
+	0x52, 0x43, 0x45, 0x5f, 0x49, 0x50, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x4c, 0x41, 0x54, 0x43,
+	0x48, 0x5f, 0x53, 0x53, 0x52, 0x43, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x4c, 0x41, 0x54, 0x43,
+	0x48, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x10, 0x03, 0x2a, 0x30, 0x0a, 0x0a, 0x56, 0x69, 0x64,
+	0x65, 0x6f, 0x43, 0x6f, 0x64, 0x65, 0x63, 0x12, 0x0e, 0x0a, 0x0a, 0x43, 0x4f, 0x44, 0x45, 0x43,
+	0x5f, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x32, 0x36, 0x34, 0x10,
+	0x01, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x32, 0x36, 0x35, 0x10, 0x02, 0x2a, 0x2d, 0x0a, 0x09, 0x4d,
+	0x65, 0x64, 0x69, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x45, 0x44, 0x49,
+	0x41, 0x5f, 0x56, 0x49, 0x44, 0x45, 0x4f, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x45, 0x44,
+	0x49, 0x41, 0x5f, 0x41, 0x55, 0x44, 0x49, 0x4f, 0x10, 0x01, 0x2a, 0xd7, 0x01, 0x0a, 0x0f, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x13,
+	0x0a, 0x0f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x4f, 0x56, 0x45,
+	0x52, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x52, 0x45, 0x41, 0x4d, 0x5f, 0x53, 0x54,
+	0x41, 0x4c, 0x4c, 0x45, 0x44, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x52, 0x45, 0x41,
+	0x4d, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4d, 0x45, 0x44, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x53,
+	0x54, 0x52, 0x45, 0x41, 0x4d, 0x5f, 0x52, 0x45, 0x4d, 0x4f, 0x56, 0x45, 0x44, 0x10, 0x03, 0x12,
+	0x12, 0x0a, 0x0e, 0x43, 0x4c, 0x49, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x4f, 0x55,
+	0x54, 0x10, 0x04, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x4c, 0x49, 0x45, 0x4e, 0x54, 0x5f, 0x55, 0x4e,
+	0x52, 0x45, 0x41, 0x43, 0x48, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x05, 0x12, 0x10, 0x0a, 0x0c, 0x53,
+	0x54, 0x52, 0x45, 0x41, 0x4d, 0x5f, 0x45, 0x4e, 0x44, 0x45, 0x44, 0x10, 0x06, 0x12, 0x0e, 0x0a,
+	0x0a, 0x43, 0x4c, 0x49, 0x45, 0x4e, 0x54, 0x5f, 0x42, 0x59, 0x45, 0x10, 0x07, 0x12, 0x12, 0x0a,
+	0x0e, 0x43, 0x4c, 0x49, 0x45, 0x4e, 0x54, 0x5f, 0x4c, 0x41, 0x54, 0x43, 0x48, 0x45, 0x44, 0x10,
+	0x08, 0x12, 0x11, 0x0a, 0x0d, 0x4c, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x4f,
+	0x55, 0x54, 0x10, 0x09, 0x32, 0xae, 0x02, 0x0a, 0x0c, 0x4d, 0x73, 0x6d, 0x44, 0x61, 0x74, 0x61,
+	0x50, 0x6c, 0x61, 0x6e, 0x65, 0x12, 0x3c, 0x0a, 0x0e, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f,
+	0x61, 0x64, 0x64, 0x5f, 0x64, 0x65, 0x6c, 0x12, 0x12, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70,
+	0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x61, 0x74, 0x61, 0x1a, 0x14, 0x2e, 0x6d, 0x73,
+	0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x22, 0x00, 0x12, 0x41, 0x0a, 0x0c, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x73, 0x12, 0x1a, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x13, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0d, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1b, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70,
+	0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x56, 0x0a,
+	0x13, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x5f, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x52, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70,
+	0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x22, 0x00, 0x32, 0x8c, 0x01, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x12, 0x40, 0x0a, 0x05, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x1a, 0x2e, 0x6d, 0x73, 0x6d, 0x5f,
+	0x64, 0x70, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x48,
+	0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x40, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1a, 0x2e, 0x6d, 0x73,
+	0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70,
 	0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x4f, 0x0a, 0x0d, 0x53, 0x65,
-	0x72, 0x76, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x0a, 0x07, 0x55,
-	0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x45, 0x52, 0x56,
-	0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x4e, 0x4f, 0x54, 0x5f, 0x53, 0x45, 0x52,
-	0x56, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x45, 0x52, 0x56, 0x49, 0x43,
-	0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x03, 0x2a, 0x59, 0x0a, 0x0f, 0x53,
-	0x74, 0x72, 0x65, 0x61, 0x6d, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a,
-	0x0a, 0x06, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50,
-	0x44, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45,
-	0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x44, 0x44, 0x5f, 0x45, 0x50, 0x10, 0x03, 0x12, 0x0a,
-	0x0a, 0x06, 0x44, 0x45, 0x4c, 0x5f, 0x45, 0x50, 0x10, 0x04, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50,
-	0x44, 0x5f, 0x45, 0x50, 0x10, 0x05, 0x2a, 0x34, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x50,
-	0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x07, 0x0a, 0x03, 0x54, 0x43, 0x50, 0x10, 0x00,
-	0x12, 0x07, 0x0a, 0x03, 0x55, 0x44, 0x50, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x51, 0x55, 0x49,
-	0x43, 0x10, 0x02, 0x12, 0x07, 0x0a, 0x03, 0x52, 0x54, 0x50, 0x10, 0x03, 0x2a, 0x91, 0x01, 0x0a,
-	0x05, 0x45, 0x6e, 0x63, 0x61, 0x70, 0x12, 0x0a, 0x0a, 0x06, 0x54, 0x43, 0x50, 0x5f, 0x49, 0x50,
-	0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x44, 0x50, 0x5f, 0x49, 0x50, 0x10, 0x01, 0x12, 0x0b,
-	0x0a, 0x07, 0x51, 0x55, 0x49, 0x43, 0x5f, 0x49, 0x50, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x52,
-	0x54, 0x50, 0x5f, 0x55, 0x44, 0x50, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x54, 0x50, 0x5f,
-	0x55, 0x44, 0x50, 0x5f, 0x4d, 0x55, 0x58, 0x10, 0x04, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x54, 0x50,
-	0x5f, 0x54, 0x43, 0x50, 0x10, 0x05, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x54, 0x50, 0x5f, 0x54, 0x43,
-	0x50, 0x5f, 0x4d, 0x55, 0x58, 0x10, 0x06, 0x12, 0x13, 0x0a, 0x0f, 0x52, 0x54, 0x50, 0x5f, 0x51,
-	0x55, 0x49, 0x43, 0x5f, 0x53, 0x54, 0x52, 0x45, 0x41, 0x4d, 0x10, 0x07, 0x12, 0x12, 0x0a, 0x0e,
-	0x52, 0x54, 0x50, 0x5f, 0x51, 0x55, 0x49, 0x43, 0x5f, 0x44, 0x47, 0x52, 0x41, 0x4d, 0x10, 0x08,
-	0x32, 0x4c, 0x0a, 0x0c, 0x4d, 0x73, 0x6d, 0x44, 0x61, 0x74, 0x61, 0x50, 0x6c, 0x61, 0x6e, 0x65,
-	0x12, 0x3c, 0x0a, 0x0e, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x61, 0x64, 0x64, 0x5f, 0x64,
-	0x65, 0x6c, 0x12, 0x12, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x53, 0x74, 0x72, 0x65,
-	0x61, 0x6d, 0x44, 0x61, 0x74, 0x61, 0x1a, 0x14, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e,
-	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x32, 0x8c,
-	0x01, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x40, 0x0a, 0x05, 0x43, 0x68, 0x65,
-	0x63, 0x6b, 0x12, 0x1a, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x48, 0x65, 0x61, 0x6c,
-	0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b,
-	0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68,
-	0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x40, 0x0a, 0x05, 0x57,
-	0x61, 0x74, 0x63, 0x68, 0x12, 0x1a, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x48, 0x65,
-	0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x1b, 0x2e, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x43, 0x5a,
-	0x41, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x65, 0x64, 0x69,
-	0x61, 0x2d, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x2d, 0x6d, 0x65, 0x73, 0x68,
-	0x2f, 0x6d, 0x73, 0x6d, 0x2d, 0x64, 0x70, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x61, 0x6c,
-	0x70, 0x68, 0x61, 0x31, 0x2f, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x3b, 0x6d, 0x73, 0x6d, 0x5f,
-	0x64, 0x70, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x43, 0x5a, 0x41, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2d, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69,
+	0x6e, 0x67, 0x2d, 0x6d, 0x65, 0x73, 0x68, 0x2f, 0x6d, 0x73, 0x6d, 0x2d, 0x64, 0x70, 0x2f, 0x61,
+	0x70, 0x69, 0x2f, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2f, 0x6d, 0x73, 0x6d, 0x5f,
+	0x64, 0x70, 0x3b, 0x6d, 0x73, 0x6d, 0x5f, 0x64, 0x70, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -660,35 +2625,83 @@ func file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescGZIP() []byte {
 	return file_api_v1alpha1_msm_dp_msm_dp_proto_rawDescData
 }
 
-var file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_api_v1alpha1_msm_dp_msm_dp_proto_enumTypes = make([]protoimpl.EnumInfo, 8)
+var file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_api_v1alpha1_msm_dp_msm_dp_proto_goTypes = []interface{}{
 	(StreamOperation)(0),                   // 0: msm_dp.StreamOperation
 	(ProxyProtocol)(0),                     // 1: msm_dp.ProxyProtocol
 	(Encap)(0),                             // 2: msm_dp.Encap
-	(HealthCheckResponse_ServingStatus)(0), // 3: msm_dp.HealthCheckResponse.ServingStatus
-	(*Endpoint)(nil),                       // 4: msm_dp.Endpoint
-	(*StreamData)(nil),                     // 5: msm_dp.StreamData
-	(*StreamResult)(nil),                   // 6: msm_dp.StreamResult
-	(*HealthCheckRequest)(nil),             // 7: msm_dp.HealthCheckRequest
-	(*HealthCheckResponse)(nil),            // 8: msm_dp.HealthCheckResponse
+	(LatchMode)(0),                         // 3: msm_dp.LatchMode
+	(VideoCodec)(0),                        // 4: msm_dp.VideoCodec
+	(MediaType)(0),                         // 5: msm_dp.MediaType
+	(StreamEventType)(0),                   // 6: msm_dp.StreamEventType
+	(HealthCheckResponse_ServingStatus)(0), // 7: msm_dp.HealthCheckResponse.ServingStatus
+	(*Endpoint)(nil),                       // 8: msm_dp.Endpoint
+	(*SrtpCrypto)(nil),                     // 9: msm_dp.SrtpCrypto
+	(*StreamData)(nil),                     // 10: msm_dp.StreamData
+	(*StreamResult)(nil),                   // 11: msm_dp.StreamResult
+	(*RegistryGenerationRequest)(nil),      // 12: msm_dp.RegistryGenerationRequest
+	(*RegistryGeneration)(nil),             // 13: msm_dp.RegistryGeneration
+	(*Registry)(nil),                       // 14: msm_dp.Registry
+	(*StreamState)(nil),                    // 15: msm_dp.StreamState
+	(*ClientState)(nil),                    // 16: msm_dp.ClientState
+	(*SrtpState)(nil),                      // 17: msm_dp.SrtpState
+	(*SrtpSsrcState)(nil),                  // 18: msm_dp.SrtpSsrcState
+	(*ReplayWindow)(nil),                   // 19: msm_dp.ReplayWindow
+	(*RewriterState)(nil),                  // 20: msm_dp.RewriterState
+	(*TranslatorState)(nil),                // 21: msm_dp.TranslatorState
+	(*ReceiverState)(nil),                  // 22: msm_dp.ReceiverState
+	(*StreamStatsRequest)(nil),             // 23: msm_dp.StreamStatsRequest
+	(*StreamStats)(nil),                    // 24: msm_dp.StreamStats
+	(*StreamEventsRequest)(nil),            // 25: msm_dp.StreamEventsRequest
+	(*StreamEvent)(nil),                    // 26: msm_dp.StreamEvent
+	(*HealthCheckRequest)(nil),             // 27: msm_dp.HealthCheckRequest
+	(*HealthCheckResponse)(nil),            // 28: msm_dp.HealthCheckResponse
+	nil,                                    // 29: msm_dp.StreamStats.CountersEntry
 }
 var file_api_v1alpha1_msm_dp_msm_dp_proto_depIdxs = []int32{
-	0, // 0: msm_dp.StreamData.operation:type_name -> msm_dp.StreamOperation
-	1, // 1: msm_dp.StreamData.protocol:type_name -> msm_dp.ProxyProtocol
-	4, // 2: msm_dp.StreamData.endpoint:type_name -> msm_dp.Endpoint
-	3, // 3: msm_dp.HealthCheckResponse.status:type_name -> msm_dp.HealthCheckResponse.ServingStatus
-	5, // 4: msm_dp.MsmDataPlane.stream_add_del:input_type -> msm_dp.StreamData
-	7, // 5: msm_dp.Health.Check:input_type -> msm_dp.HealthCheckRequest
-	7, // 6: msm_dp.Health.Watch:input_type -> msm_dp.HealthCheckRequest
-	6, // 7: msm_dp.MsmDataPlane.stream_add_del:output_type -> msm_dp.StreamResult
-	8, // 8: msm_dp.Health.Check:output_type -> msm_dp.HealthCheckResponse
-	8, // 9: msm_dp.Health.Watch:output_type -> msm_dp.HealthCheckResponse
-	7, // [7:10] is the sub-list for method output_type
-	4, // [4:7] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	9,  // 0: msm_dp.Endpoint.srtp:type_name -> msm_dp.SrtpCrypto
+	0,  // 1: msm_dp.StreamData.operation:type_name -> msm_dp.StreamOperation
+	1,  // 2: msm_dp.StreamData.protocol:type_name -> msm_dp.ProxyProtocol
+	8,  // 3: msm_dp.StreamData.endpoint:type_name -> msm_dp.Endpoint
+	4,  // 4: msm_dp.StreamData.codec:type_name -> msm_dp.VideoCodec
+	8,  // 5: msm_dp.StreamData.redundant_sources:type_name -> msm_dp.Endpoint
+	8,  // 6: msm_dp.StreamData.backup_sources:type_name -> msm_dp.Endpoint
+	3,  // 7: msm_dp.StreamData.latch:type_name -> msm_dp.LatchMode
+	5,  // 8: msm_dp.StreamData.media:type_name -> msm_dp.MediaType
+	10, // 9: msm_dp.Registry.requests:type_name -> msm_dp.StreamData
+	15, // 10: msm_dp.Registry.states:type_name -> msm_dp.StreamState
+	17, // 11: msm_dp.StreamState.source_srtp:type_name -> msm_dp.SrtpState
+	20, // 12: msm_dp.StreamState.rewriter:type_name -> msm_dp.RewriterState
+	21, // 13: msm_dp.StreamState.translator:type_name -> msm_dp.TranslatorState
+	16, // 14: msm_dp.StreamState.clients:type_name -> msm_dp.ClientState
+	17, // 15: msm_dp.ClientState.srtp:type_name -> msm_dp.SrtpState
+	18, // 16: msm_dp.SrtpState.sent:type_name -> msm_dp.SrtpSsrcState
+	18, // 17: msm_dp.SrtpState.received:type_name -> msm_dp.SrtpSsrcState
+	19, // 18: msm_dp.SrtpSsrcState.replay:type_name -> msm_dp.ReplayWindow
+	19, // 19: msm_dp.SrtpSsrcState.rtcp_replay:type_name -> msm_dp.ReplayWindow
+	22, // 20: msm_dp.TranslatorState.source:type_name -> msm_dp.ReceiverState
+	29, // 21: msm_dp.StreamStats.counters:type_name -> msm_dp.StreamStats.CountersEntry
+	6,  // 22: msm_dp.StreamEvent.type:type_name -> msm_dp.StreamEventType
+	8,  // 23: msm_dp.StreamEvent.endpoint:type_name -> msm_dp.Endpoint
+	7,  // 24: msm_dp.HealthCheckResponse.status:type_name -> msm_dp.HealthCheckResponse.ServingStatus
+	10, // 25: msm_dp.MsmDataPlane.stream_add_del:input_type -> msm_dp.StreamData
+	23, // 26: msm_dp.MsmDataPlane.stream_stats:input_type -> msm_dp.StreamStatsRequest
+	25, // 27: msm_dp.MsmDataPlane.stream_events:input_type -> msm_dp.StreamEventsRequest
+	12, // 28: msm_dp.MsmDataPlane.registry_generation:input_type -> msm_dp.RegistryGenerationRequest
+	27, // 29: msm_dp.Health.Check:input_type -> msm_dp.HealthCheckRequest
+	27, // 30: msm_dp.Health.Watch:input_type -> msm_dp.HealthCheckRequest
+	11, // 31: msm_dp.MsmDataPlane.stream_add_del:output_type -> msm_dp.StreamResult
+	24, // 32: msm_dp.MsmDataPlane.stream_stats:output_type -> msm_dp.StreamStats
+	26, // 33: msm_dp.MsmDataPlane.stream_events:output_type -> msm_dp.StreamEvent
+	13, // 34: msm_dp.MsmDataPlane.registry_generation:output_type -> msm_dp.RegistryGeneration
+	28, // 35: msm_dp.Health.Check:output_type -> msm_dp.HealthCheckResponse
+	28, // 36: msm_dp.Health.Watch:output_type -> msm_dp.HealthCheckResponse
+	31, // [31:37] is the sub-list for method output_type
+	25, // [25:31] is the sub-list for method input_type
+	25, // [25:25] is the sub-list for extension type_name
+	25, // [25:25] is the sub-list for extension extendee
+	0,  // [0:25] is the sub-list for field type_name
 }
 
 func init() { file_api_v1alpha1_msm_dp_msm_dp_proto_init() }
@@ -710,7 +2723,7 @@ func file_api_v1alpha1_msm_dp_msm_dp_proto_init() {
 			}
 		}
 		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StreamData); i {
+			switch v := v.(*SrtpCrypto); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -722,7 +2735,7 @@ func file_api_v1alpha1_msm_dp_msm_dp_proto_init() {
 			}
 		}
 		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StreamResult); i {
+			switch v := v.(*StreamData); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -734,7 +2747,7 @@ func file_api_v1alpha1_msm_dp_msm_dp_proto_init() {
 			}
 		}
 		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HealthCheckRequest); i {
+			switch v := v.(*StreamResult); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -746,6 +2759,198 @@ func file_api_v1alpha1_msm_dp_msm_dp_proto_init() {
 			}
 		}
 		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegistryGenerationRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegistryGeneration); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Registry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SrtpState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SrtpSsrcState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplayWindow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RewriterState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TranslatorState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReceiverState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthCheckRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*HealthCheckResponse); i {
 			case 0:
 				return &v.state
@@ -758,13 +2963,14 @@ func file_api_v1alpha1_msm_dp_msm_dp_proto_init() {
 			}
 		}
 	}
+	file_api_v1alpha1_msm_dp_msm_dp_proto_msgTypes[2].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_v1alpha1_msm_dp_msm_dp_proto_rawDesc,
-			NumEnums:      4,
-			NumMessages:   5,
+			NumEnums:      8,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
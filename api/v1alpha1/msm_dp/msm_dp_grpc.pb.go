@@ -23,6 +23,9 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MsmDataPlaneClient interface {
 	StreamAddDel(ctx context.Context, in *StreamData, opts ...grpc.CallOption) (*StreamResult, error)
+	StreamStats(ctx context.Context, in *StreamStatsRequest, opts ...grpc.CallOption) (*StreamStats, error)
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (MsmDataPlane_StreamEventsClient, error)
+	RegistryGeneration(ctx context.Context, in *RegistryGenerationRequest, opts ...grpc.CallOption) (*RegistryGeneration, error)
 }
 
 type msmDataPlaneClient struct {
@@ -42,11 +45,64 @@ func (c *msmDataPlaneClient) StreamAddDel(ctx context.Context, in *StreamData, o
 	return out, nil
 }
 
+func (c *msmDataPlaneClient) StreamStats(ctx context.Context, in *StreamStatsRequest, opts ...grpc.CallOption) (*StreamStats, error) {
+	out := new(StreamStats)
+	err := c.cc.Invoke(ctx, "/msm_dp.MsmDataPlane/stream_stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *msmDataPlaneClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (MsmDataPlane_StreamEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MsmDataPlane_ServiceDesc.Streams[0], "/msm_dp.MsmDataPlane/stream_events", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &msmDataPlaneStreamEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MsmDataPlane_StreamEventsClient interface {
+	Recv() (*StreamEvent, error)
+	grpc.ClientStream
+}
+
+type msmDataPlaneStreamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *msmDataPlaneStreamEventsClient) Recv() (*StreamEvent, error) {
+	m := new(StreamEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *msmDataPlaneClient) RegistryGeneration(ctx context.Context, in *RegistryGenerationRequest, opts ...grpc.CallOption) (*RegistryGeneration, error) {
+	out := new(RegistryGeneration)
+	err := c.cc.Invoke(ctx, "/msm_dp.MsmDataPlane/registry_generation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MsmDataPlaneServer is the server API for MsmDataPlane service.
 // All implementations must embed UnimplementedMsmDataPlaneServer
 // for forward compatibility
 type MsmDataPlaneServer interface {
 	StreamAddDel(context.Context, *StreamData) (*StreamResult, error)
+	StreamStats(context.Context, *StreamStatsRequest) (*StreamStats, error)
+	StreamEvents(*StreamEventsRequest, MsmDataPlane_StreamEventsServer) error
+	RegistryGeneration(context.Context, *RegistryGenerationRequest) (*RegistryGeneration, error)
 	mustEmbedUnimplementedMsmDataPlaneServer()
 }
 
@@ -57,6 +113,15 @@ type UnimplementedMsmDataPlaneServer struct {
 func (UnimplementedMsmDataPlaneServer) StreamAddDel(context.Context, *StreamData) (*StreamResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StreamAddDel not implemented")
 }
+func (UnimplementedMsmDataPlaneServer) StreamStats(context.Context, *StreamStatsRequest) (*StreamStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StreamStats not implemented")
+}
+func (UnimplementedMsmDataPlaneServer) StreamEvents(*StreamEventsRequest, MsmDataPlane_StreamEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedMsmDataPlaneServer) RegistryGeneration(context.Context, *RegistryGenerationRequest) (*RegistryGeneration, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegistryGeneration not implemented")
+}
 func (UnimplementedMsmDataPlaneServer) mustEmbedUnimplementedMsmDataPlaneServer() {}
 
 // UnsafeMsmDataPlaneServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +153,63 @@ func _MsmDataPlane_StreamAddDel_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MsmDataPlane_StreamStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StreamStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsmDataPlaneServer).StreamStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/msm_dp.MsmDataPlane/stream_stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsmDataPlaneServer).StreamStats(ctx, req.(*StreamStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MsmDataPlane_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MsmDataPlaneServer).StreamEvents(m, &msmDataPlaneStreamEventsServer{stream})
+}
+
+type MsmDataPlane_StreamEventsServer interface {
+	Send(*StreamEvent) error
+	grpc.ServerStream
+}
+
+type msmDataPlaneStreamEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *msmDataPlaneStreamEventsServer) Send(m *StreamEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MsmDataPlane_RegistryGeneration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegistryGenerationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MsmDataPlaneServer).RegistryGeneration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/msm_dp.MsmDataPlane/registry_generation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MsmDataPlaneServer).RegistryGeneration(ctx, req.(*RegistryGenerationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MsmDataPlane_ServiceDesc is the grpc.ServiceDesc for MsmDataPlane service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,8 +221,22 @@ var MsmDataPlane_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "stream_add_del",
 			Handler:    _MsmDataPlane_StreamAddDel_Handler,
 		},
+		{
+			MethodName: "stream_stats",
+			Handler:    _MsmDataPlane_StreamStats_Handler,
+		},
+		{
+			MethodName: "registry_generation",
+			Handler:    _MsmDataPlane_RegistryGeneration_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "stream_events",
+			Handler:       _MsmDataPlane_StreamEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1alpha1/msm_dp/msm_dp.proto",
 }
 
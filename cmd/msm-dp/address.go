@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net"
+	"net/netip"
+)
+
+// parseAddress parses an address received from the control plane. The IP may
+// be IPv4 or IPv6, with a zone for link-local addresses, e.g. "fe80::1%eth0".
+// An invalid IP gives an address with no IP.
+func parseAddress(ip string, port uint32) net.UDPAddr {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return net.UDPAddr{Port: int(port)}
+	}
+	return *net.UDPAddrFromAddrPort(netip.AddrPortFrom(addr.Unmap(), uint16(port)))
+}
+
+// addressKey returns a comparable key for an address. IPv4 addresses received
+// on the dual-stack sockets as IPv4-mapped IPv6 addresses have the same key
+// as the plain IPv4 address.
+func addressKey(address net.UDPAddr) netip.AddrPort {
+	addrPort := address.AddrPort()
+	return netip.AddrPortFrom(addrPort.Addr().Unmap(), addrPort.Port())
+}
+
+func sameAddress(a, b net.UDPAddr) bool {
+	return addressKey(a) == addressKey(b)
+}
+
+// sameIP compares the IPs of two addresses, including the zone of IPv6 link-local addresses.
+func sameIP(a, b net.UDPAddr) bool {
+	return addressKey(a).Addr() == addressKey(b).Addr()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+// listenLocalUDP returns a socket on a free loopback port, closed when the
+// test ends.
+func listenLocalUDP(t *testing.T) *net.UDPConn {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestParseAddress(t *testing.T) {
+	v4 := parseAddress("192.0.2.1", 5000)
+	require.Equal(t, "192.0.2.1:5000", v4.String())
+	require.True(t, sameAddress(v4, net.UDPAddr{IP: net.ParseIP("::ffff:192.0.2.1"), Port: 5000}), "IPv4-mapped address")
+
+	v6 := parseAddress("2001:db8::1", 5000)
+	require.Equal(t, "[2001:db8::1]:5000", v6.String())
+	require.NotEqual(t, addressKey(v4), addressKey(v6))
+
+	linkLocal := parseAddress("fe80::1%eth0", 5000)
+	require.Equal(t, "eth0", linkLocal.Zone)
+	require.False(t, sameAddress(linkLocal, parseAddress("fe80::1%eth1", 5000)))
+
+	require.Nil(t, parseAddress("not an IP", 5000).IP)
+}
+
+// TestDualStackForwarding forwards from an IPv6 source to IPv4 and IPv6
+// clients, and from an IPv4 source, through one dual-stack socket.
+func TestDualStackForwarding(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv6unspecified})
+	if err != nil {
+		t.Skip("IPv6 is not available")
+	}
+	defer conn.Close()
+	port := uint32(conn.LocalAddr().(*net.UDPAddr).Port)
+
+	listen := func(network, ip string) *net.UDPConn {
+		c, err := net.ListenUDP(network, &net.UDPAddr{IP: net.ParseIP(ip)})
+		if err != nil {
+			t.Skipf("%s is not available", ip)
+		}
+		return c
+	}
+	source6, source4 := listen("udp6", "::1"), listen("udp4", "127.0.0.1")
+	client6, client4 := listen("udp6", "::1"), listen("udp4", "127.0.0.1")
+	for _, c := range []*net.UDPConn{source6, source4, client6, client4} {
+		defer c.Close()
+	}
+
+	s := &server{}
+	endpoint := func(c *net.UDPConn) *pb.Endpoint {
+		address := c.LocalAddr().(*net.UDPAddr)
+		return &pb.Endpoint{Ip: address.IP.String(), Port: uint32(address.Port)}
+	}
+	for id, src := range map[uint32]*net.UDPConn{10: source6, 11: source4} {
+		_, err = s.StreamAddDel(context.Background(), &pb.StreamData{Id: id, Operation: pb.StreamOperation_CREATE, Endpoint: endpoint(src)})
+		require.NoError(t, err)
+		defer s.StreamAddDel(context.Background(), &pb.StreamData{Id: id, Operation: pb.StreamOperation_DELETE})
+		for _, client := range []*net.UDPConn{client6, client4} {
+			_, err = s.StreamAddDel(context.Background(), &pb.StreamData{Id: id, Operation: pb.StreamOperation_ADD_EP, Endpoint: endpoint(client), Enable: true})
+			require.NoError(t, err)
+		}
+	}
+
+	buffer := make([]byte, 1500)
+	for seq, src := range []*net.UDPConn{source6, source4} {
+		destination := &net.UDPAddr{IP: src.LocalAddr().(*net.UDPAddr).IP, Port: int(port)}
+		_, err = src.WriteToUDP(rtpPacket(uint16(seq), 0), destination)
+		require.NoError(t, err)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, sourceAddr, err := conn.ReadFromUDP(buffer)
+		require.NoError(t, err)
+		streamsLock.Lock()
+		forwardRTPPacket(conn, buffer[:n], sourceAddr)
+		streamsLock.Unlock()
+
+		for _, client := range []*net.UDPConn{client6, client4} {
+			require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+			n, err := client.Read(buffer)
+			require.NoError(t, err)
+			require.Equal(t, uint16(seq), rtpSequence(buffer[:n]))
+		}
+	}
+}
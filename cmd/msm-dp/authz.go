@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+// caller is the identity of a control plane client.
+type caller struct {
+	// names are the subject alternative names and common name of its certificate
+	names []string
+	// token is the bearer token of the request's authorization metadata
+	token string
+}
+
+func (c *caller) String() string {
+	if len(c.names) == 0 && c.token != "" {
+		// tokens are secrets, they aren't logged
+		return "bearer token"
+	}
+	if len(c.names) == 0 {
+		return "anonymous"
+	}
+	return strings.Join(c.names, ",")
+}
+
+// callerFromContext returns the identity of the client of an RPC.
+func callerFromContext(ctx context.Context) *caller {
+	c := &caller{}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+			cert := info.State.PeerCertificates[0]
+			for _, uri := range cert.URIs {
+				c.names = append(c.names, uri.String())
+			}
+			c.names = append(c.names, cert.DNSNames...)
+			c.names = append(c.names, cert.EmailAddresses...)
+			for _, ip := range cert.IPAddresses {
+				c.names = append(c.names, ip.String())
+			}
+			if cert.Subject.CommonName != "" {
+				c.names = append(c.names, cert.Subject.CommonName)
+			}
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+				c.token = token
+			}
+		}
+	}
+	return c
+}
+
+// authorizer decides which control requests a caller may make.
+type authorizer interface {
+	// authorize returns why the request is denied, or nil if it is allowed.
+	authorize(c *caller, method string, request any) error
+}
+
+// authorizationOptions returns the interceptors that check every RPC but the
+// health checks with the authorizer.
+func authorizationOptions(a authorizer) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := checkRequest(ctx, a, info.FullMethod, req); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &authorizedStream{ServerStream: ss, authorizer: a, method: info.FullMethod})
+		}),
+	}
+}
+
+// authorizedStream checks the requests received on a streaming RPC.
+type authorizedStream struct {
+	grpc.ServerStream
+	authorizer authorizer
+	method     string
+}
+
+func (s *authorizedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return checkRequest(s.Context(), s.authorizer, s.method, m)
+}
+
+func checkRequest(ctx context.Context, a authorizer, method string, request any) error {
+	if strings.HasPrefix(method, "/grpc.health.v1.") {
+		return nil
+	}
+	c := callerFromContext(ctx)
+	if err := a.authorize(c, method, request); err != nil {
+		fields := log.Fields{"audit": "denied", "caller": c.String(), "method": method}
+		if p, ok := peer.FromContext(ctx); ok {
+			fields["peer"] = p.Addr.String()
+		}
+		if in, ok := request.(*pb.StreamData); ok {
+			fields["operation"] = in.Operation.String()
+			fields["stream"] = in.Id
+			fields["endpoint"] = fmt.Sprintf("%s:%d", in.Endpoint.GetIp(), in.Endpoint.GetPort())
+		}
+		log.WithFields(fields).WithError(err).Warn("Control request denied")
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return nil
+}
+
+// policy is the file based authorizer. A request is allowed when one of the
+// rules allows it.
+type policy struct {
+	// Tokens maps bearer tokens to the identity of their holder
+	Tokens map[string]string `json:"tokens"`
+	Rules  []*policyRule     `json:"rules"`
+}
+
+// policyRule allows its identities the operations on the streams, sources and
+// destinations, each of which is unrestricted when empty. An identity of "*"
+// matches any caller.
+type policyRule struct {
+	Identities []string `json:"identities"`
+	// Operations are stream operations, such as ADD_EP, or RPC names, such as stream_stats
+	Operations []string `json:"operations"`
+	// Streams are stream IDs or ranges of IDs, such as "100-199"
+	Streams []string `json:"streams"`
+	// Sources are the CIDRs that the sources of CREATE and UPDATE requests,
+	// backup and redundant ones included, must be in
+	Sources []string `json:"sources"`
+	// Destinations are the CIDRs that the clients of ADD_EP, UPD_EP and DEL_EP
+	// requests must be in
+	Destinations []string `json:"destinations"`
+
+	streams      [][2]uint32
+	sources      []netip.Prefix
+	destinations []netip.Prefix
+}
+
+// loadPolicy reads a JSON policy file.
+func loadPolicy(file string) (*policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	p := &policy{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	for i, rule := range p.Rules {
+		if err := rule.parse(); err != nil {
+			return nil, fmt.Errorf("invalid rule %d of policy %s: %w", i, file, err)
+		}
+	}
+	return p, nil
+}
+
+func (r *policyRule) parse() error {
+	for _, ids := range r.Streams {
+		first, last, isRange := strings.Cut(ids, "-")
+		low, err := strconv.ParseUint(strings.TrimSpace(first), 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid stream %q", ids)
+		}
+		high := low
+		if isRange {
+			if high, err = strconv.ParseUint(strings.TrimSpace(last), 10, 32); err != nil || high < low {
+				return fmt.Errorf("invalid stream range %q", ids)
+			}
+		}
+		r.streams = append(r.streams, [2]uint32{uint32(low), uint32(high)})
+	}
+	var err error
+	if r.sources, err = parsePrefixes(r.Sources); err != nil {
+		return err
+	}
+	r.destinations, err = parsePrefixes(r.Destinations)
+	return err
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func (p *policy) authorize(c *caller, method string, request any) error {
+	names := c.names
+	if name, ok := p.Tokens[c.token]; ok && c.token != "" {
+		names = append(slices.Clone(names), name)
+	}
+	operation := path.Base(method)
+	var streamID *uint32
+	var sources, destinations []*pb.Endpoint
+	switch in := request.(type) {
+	case *pb.StreamData:
+		operation = in.Operation.String()
+		streamID = &in.Id
+		switch in.Operation {
+		case pb.StreamOperation_CREATE, pb.StreamOperation_UPDATE:
+			sources = append(append([]*pb.Endpoint{in.Endpoint}, in.RedundantSources...), in.BackupSources...)
+		case pb.StreamOperation_ADD_EP, pb.StreamOperation_UPD_EP, pb.StreamOperation_DEL_EP:
+			destinations = []*pb.Endpoint{in.Endpoint}
+		}
+	case *pb.StreamStatsRequest:
+		streamID = &in.Id
+	}
+
+	for _, rule := range p.Rules {
+		if rule.allows(names, operation, streamID, sources, destinations) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no rule allows %s", operation)
+}
+
+func (r *policyRule) allows(names []string, operation string, streamID *uint32, sources, destinations []*pb.Endpoint) bool {
+	if !slices.Contains(r.Identities, "*") && !slices.ContainsFunc(names, func(name string) bool {
+		return slices.Contains(r.Identities, name)
+	}) {
+		return false
+	}
+	if len(r.Operations) > 0 && !slices.Contains(r.Operations, operation) {
+		return false
+	}
+	if len(r.streams) > 0 {
+		if streamID == nil || !slices.ContainsFunc(r.streams, func(ids [2]uint32) bool {
+			return *streamID >= ids[0] && *streamID <= ids[1]
+		}) {
+			return false
+		}
+	}
+	return inPrefixes(r.sources, sources) && inPrefixes(r.destinations, destinations)
+}
+
+// inPrefixes reports whether the endpoints are all in the prefixes, or the
+// prefixes are empty.
+func inPrefixes(prefixes []netip.Prefix, endpoints []*pb.Endpoint) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, endpoint := range endpoints {
+		if endpoint == nil {
+			continue
+		}
+		addr, ok := netip.AddrFromSlice(parseAddress(endpoint.Ip, endpoint.Port).IP)
+		if !ok || !slices.ContainsFunc(prefixes, func(prefix netip.Prefix) bool {
+			return prefix.Contains(addr.Unmap())
+		}) {
+			return false
+		}
+	}
+	return true
+}
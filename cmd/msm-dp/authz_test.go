@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+const testPolicy = `{
+	"tokens": {"cp-token": "control-plane", "ro-token": "dashboard"},
+	"rules": [
+		{"identities": ["control-plane"], "streams": ["100-199"], "sources": ["10.0.0.0/8", "172.16.0.0/12"], "destinations": ["10.0.0.0/8", "fd00::/8"]},
+		{"identities": ["dashboard", "spiffe://mesh/monitor"], "operations": ["stream_stats", "stream_events"]}
+	]
+}`
+
+func writePolicy(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	return file
+}
+
+func TestPolicy(t *testing.T) {
+	p, err := loadPolicy(writePolicy(t, testPolicy))
+	require.NoError(t, err)
+
+	controlPlane := &caller{token: "cp-token"}
+	addClient := func(id uint32, ip string) *pb.StreamData {
+		return &pb.StreamData{Id: id, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: ip, Port: 5000}}
+	}
+	const addDel = "/msm_dp.MsmDataPlane/stream_add_del"
+	require.NoError(t, p.authorize(controlPlane, addDel, addClient(150, "10.1.2.3")))
+	require.NoError(t, p.authorize(controlPlane, addDel, addClient(150, "fd00::1")))
+	require.NoError(t, p.authorize(controlPlane, addDel, &pb.StreamData{Id: 150, Operation: pb.StreamOperation_DELETE}))
+	require.Error(t, p.authorize(controlPlane, addDel, addClient(200, "10.1.2.3")), "stream outside the range")
+	require.Error(t, p.authorize(controlPlane, addDel, addClient(150, "192.168.1.1")), "destination outside the CIDRs")
+	require.Error(t, p.authorize(controlPlane, addDel, &pb.StreamData{
+		Id: 150, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "10.1.2.3", Port: 5000},
+		BackupSources: []*pb.Endpoint{{Ip: "192.168.1.1", Port: 5000}},
+	}), "every source of the request must be allowed")
+	require.NoError(t, p.authorize(controlPlane, addDel, &pb.StreamData{
+		Id: 150, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "172.16.1.1", Port: 5000},
+	}), "sources aren't checked against the destinations")
+	require.Error(t, p.authorize(controlPlane, addDel, addClient(150, "172.16.1.1")), "clients aren't checked against the sources")
+
+	dashboard := &caller{token: "ro-token"}
+	require.NoError(t, p.authorize(dashboard, "/msm_dp.MsmDataPlane/stream_stats", &pb.StreamStatsRequest{Id: 1}))
+	require.Error(t, p.authorize(dashboard, addDel, addClient(150, "10.1.2.3")))
+	require.NoError(t, p.authorize(&caller{names: []string{"spiffe://mesh/monitor"}}, "/msm_dp.MsmDataPlane/stream_events", &pb.StreamEventsRequest{}))
+	require.Error(t, p.authorize(&caller{token: "wrong"}, "/msm_dp.MsmDataPlane/stream_stats", &pb.StreamStatsRequest{Id: 1}))
+	require.Error(t, p.authorize(&caller{}, "/msm_dp.MsmDataPlane/stream_stats", &pb.StreamStatsRequest{Id: 1}))
+
+	_, err = loadPolicy(writePolicy(t, `{"rules": [{"identities": ["*"], "streams": ["9-1"]}]}`))
+	require.Error(t, err)
+	_, err = loadPolicy(writePolicy(t, `{"rules": [{"identities": ["*"], "destinations": ["10.0.0.0"]}]}`))
+	require.Error(t, err)
+	_, err = loadPolicy(writePolicy(t, `{"rules": [{"identities": ["*"], "sources": ["bogus"]}]}`))
+	require.Error(t, err)
+}
+
+func TestAuthorizationInterceptors(t *testing.T) {
+	p, err := loadPolicy(writePolicy(t, testPolicy))
+	require.NoError(t, err)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer(authorizationOptions(p)...)
+	pb.RegisterMsmDataPlaneServer(s, &server{})
+	grpc_health_v1.RegisterHealthServer(s, NewHealthChecker())
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewMsmDataPlaneClient(conn)
+	withToken := func(token string) (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token), cancel
+	}
+
+	ctx, cancel := withToken("ro-token")
+	defer cancel()
+	_, err = client.StreamAddDel(ctx, &pb.StreamData{Id: 150, Operation: pb.StreamOperation_DELETE})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.StreamStats(ctx, &pb.StreamStatsRequest{Id: 150})
+	require.Equal(t, codes.NotFound, status.Code(err), "allowed requests reach the server")
+
+	ctx, cancel = withToken("cp-token")
+	defer cancel()
+	result, err := client.StreamAddDel(ctx, &pb.StreamData{Id: 150, Operation: pb.StreamOperation_DELETE})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	events, err := client.StreamEvents(ctx, &pb.StreamEventsRequest{})
+	require.NoError(t, err)
+	_, err = events.Recv()
+	require.Equal(t, codes.PermissionDenied, status.Code(err), "streaming requests are checked too")
+
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	require.NotEqual(t, codes.PermissionDenied, status.Code(err), "health checks are not authorized")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+	log "github.com/sirupsen/logrus"
+)
+
+// sourceBYE handles an RTCP BYE from a source and reports whether the stream
+// ended. The stream ends when all of its redundant sources have left, or when
+// its active source has left and no backup source is live to fail over to.
+func sourceBYE(streamID uint32, stream *Stream, src *source, reason string) bool {
+	src.bye = true
+	stream.stats.add("source_byes", 1)
+	if stream.merger != nil {
+		for _, s := range stream.sources {
+			if !s.bye {
+				log.Infof("Source %v of stream %v left: %q", src.address, streamID, reason)
+				return false
+			}
+		}
+	}
+	if stream.failoverTimeout > 0 && failover(streamID, stream, time.Now(), fmt.Sprintf("%v left: %q", src.address, reason)) {
+		return false
+	}
+	if stream.ended {
+		return true
+	}
+	stream.ended = true
+	log.Infof("Stream %v ended by source %v: %q", streamID, src.address, reason)
+	events.publish(endpointEvent(streamID, pb.StreamEventType_STREAM_ENDED, src.address, reason))
+	return true
+}
+
+// sourceRestarted handles an RTP packet from a source that has sent a BYE.
+func sourceRestarted(streamID uint32, stream *Stream, src *source) {
+	src.bye = false
+	if stream.ended {
+		stream.ended = false
+		log.Infof("Stream %v restarted by source %v", streamID, src.address)
+	}
+}
+
+// clientBYE disables a client that has sent an RTCP BYE.
+func clientBYE(streamID uint32, stream *Stream, endpoint *Endpoint, reason string) {
+	endpoint.enabled = false
+	stream.stats.add("client_byes", 1)
+	log.Infof("Client %v left stream %v: %q", endpoint.address, streamID, reason)
+	events.publish(endpointEvent(streamID, pb.StreamEventType_CLIENT_BYE, endpoint.address, reason))
+}
+
+// sendTranslatorBYE sends a final sender report and a BYE to the clients of a
+// stream whose RTCP is terminated by msm-dp.
+func sendTranslatorBYE(rtcpConn *net.UDPConn, stream *Stream, now time.Time) {
+	t := stream.translator
+	for _, endpoint := range stream.clients {
+		if !endpoint.enabled {
+			continue
+		}
+		packet := append(t.senderReport(t.outSSRC, endpoint, now), byePacket(t.outSSRC)...)
+		if err := endpoint.writeRTCP(rtcpConn, packet); err != nil {
+			log.WithError(err).Warn("Could not send RTCP BYE.")
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func rtcpBYEPacket(ssrc uint32, reason string) []byte {
+	rr := []byte{0x80, rtcpRR, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01}
+	bye := byePacket(ssrc)
+	bye = append(bye, byte(len(reason)))
+	bye = append(bye, reason...)
+	for len(bye)%4 != 0 {
+		bye = append(bye, 0)
+	}
+	bye[3] = byte(len(bye)/4 - 1)
+	return append(rr, bye...)
+}
+
+func TestFindBYE(t *testing.T) {
+	bye, reason := findBYE(rtcpBYEPacket(0x1234, "camera off"))
+	require.True(t, bye)
+	require.Equal(t, "camera off", reason)
+
+	bye, _ = findBYE([]byte{0x80, rtcpRR, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01})
+	require.False(t, bye)
+}
+
+func TestSourceAndClientBYE(t *testing.T) {
+	conn := listenLocalUDP(t)
+
+	src := newSource("127.0.0.1", 7200)
+	clientAddr := net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7300}
+	endpoint := &Endpoint{enabled: true, address: clientAddr}
+	stream := &Stream{sources: []*source{src}, clients: map[string]*Endpoint{clientAddr.String(): endpoint}, stats: newCounters()}
+
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	streams[8] = stream
+	streamMap[addressKey(src.address)] = 8
+	defer func() {
+		delete(streams, 8)
+		delete(streamMap, addressKey(src.address))
+	}()
+
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	forwardRTCPPacket(conn, conn, rtcpBYEPacket(0x1234, "bye"), &net.UDPAddr{IP: src.address.IP, Port: src.address.Port + 1})
+	require.True(t, stream.ended)
+	event := <-ch
+	require.Equal(t, pb.StreamEventType_STREAM_ENDED, event.Type)
+	require.Equal(t, "bye", event.Message)
+
+	forwardRTPPacket(conn, rtpPacket(1, 0), &src.address)
+	require.False(t, stream.ended, "the source is sending again")
+
+	forwardRTCPPacket(conn, conn, rtcpBYEPacket(0x5678, ""), &net.UDPAddr{IP: clientAddr.IP, Port: clientAddr.Port + 1})
+	require.False(t, endpoint.enabled)
+	require.Equal(t, pb.StreamEventType_CLIENT_BYE, (<-ch).Type)
+}
+
+func TestSourceBYEFailover(t *testing.T) {
+	now := time.Now()
+	primary := newSource("127.0.0.1", 7010)
+	backup := newSource("127.0.0.1", 7012)
+	stream := &Stream{
+		sources:         []*source{primary, backup},
+		failoverTimeout: 500 * time.Millisecond,
+		created:         now,
+		stats:           newCounters(),
+	}
+
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	backup.lastPacket = now
+	require.False(t, sourceBYE(24, stream, primary, "camera off"))
+	require.Equal(t, 1, stream.active, "a live backup takes over")
+	require.False(t, stream.ended)
+	event := <-ch
+	require.Equal(t, pb.StreamEventType_SOURCE_FAILOVER, event.Type)
+	require.Equal(t, uint32(7012), event.Endpoint.Port)
+
+	require.True(t, sourceBYE(24, stream, backup, "camera off"), "no backup left")
+	require.True(t, stream.ended)
+	require.Equal(t, pb.StreamEventType_STREAM_ENDED, (<-ch).Type)
+
+	primary.bye = false
+	primary.lastPacket = time.Now()
+	checkFailover(24, stream, time.Now())
+	require.Equal(t, 0, stream.active, "a backup coming back resumes the stream")
+	require.False(t, stream.ended)
+	require.Equal(t, pb.StreamEventType_SOURCE_FAILOVER, (<-ch).Type)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+	log "github.com/sirupsen/logrus"
+)
+
+// unreachableThreshold is the number of ICMP unreachable errors after which
+// a client is considered gone.
+const unreachableThreshold = 3
+
+// revive resets the liveness of a client that has been enabled again.
+func (e *Endpoint) revive(now time.Time) {
+	e.added = now
+	e.lastRTCP = time.Time{}
+	e.unreachable = 0
+	e.dead = false
+}
+
+// clientGone reports a client that is no longer receiving the stream and
+// disables it if the stream is configured to do so.
+func clientGone(streamID uint32, stream *Stream, endpoint *Endpoint, eventType pb.StreamEventType, message string) {
+	endpoint.dead = true
+	stream.stats.add("dead_clients", 1)
+	if stream.disableDeadClients {
+		endpoint.enabled = false
+		log.Warnf("Client %v of stream %v disabled: %s", endpoint.address, streamID, message)
+	} else {
+		log.Warnf("Client %v of stream %v is gone: %s", endpoint.address, streamID, message)
+	}
+	events.publish(endpointEvent(streamID, eventType, endpoint.address, message))
+}
+
+// checkClients reports the enabled clients of the stream that haven't sent RTCP for the client timeout.
+func checkClients(streamID uint32, stream *Stream, now time.Time) {
+	for _, endpoint := range stream.clients {
+		// multicast clients are not expected to send RTCP from the group address
+		if !endpoint.enabled || endpoint.dead || endpoint.multicast != nil {
+			continue
+		}
+		lastRTCP := endpoint.lastRTCP
+		if lastRTCP.IsZero() {
+			lastRTCP = endpoint.added
+		}
+		if idle := now.Sub(lastRTCP); idle > stream.clientTimeout {
+			message := fmt.Sprintf("no RTCP for %v", idle.Round(time.Millisecond))
+			clientGone(streamID, stream, endpoint, pb.StreamEventType_CLIENT_TIMEOUT, message)
+		}
+	}
+}
+
+// clientUnreachable records an ICMP unreachable error for the clients with the given address.
+// Must be called with streamsLock held.
+func clientUnreachable(address net.UDPAddr) {
+	for streamID, stream := range streams {
+		for _, endpoint := range stream.clients {
+			if !sameAddress(endpoint.address, address) {
+				continue
+			}
+			endpoint.unreachable++
+			log.Debugf("Client %v of stream %v is unreachable", address, streamID)
+			if endpoint.unreachable >= unreachableThreshold && endpoint.enabled && !endpoint.dead {
+				message := fmt.Sprintf("%d ICMP unreachable errors", endpoint.unreachable)
+				clientGone(streamID, stream, endpoint, pb.StreamEventType_CLIENT_UNREACHABLE, message)
+			}
+		}
+	}
+}
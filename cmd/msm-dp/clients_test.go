@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestClientTimeout(t *testing.T) {
+	now := time.Now()
+	quiet := &Endpoint{enabled: true, added: now}
+	reporting := &Endpoint{enabled: true, added: now, lastRTCP: now.Add(4 * time.Second)}
+	stream := &Stream{
+		clients:            map[string]*Endpoint{"quiet": quiet, "reporting": reporting},
+		clientTimeout:      2 * time.Second,
+		disableDeadClients: true,
+		stats:              newCounters(),
+	}
+
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	checkClients(6, stream, now.Add(5*time.Second))
+	require.False(t, quiet.enabled)
+	require.True(t, quiet.dead)
+	require.True(t, reporting.enabled)
+	require.Equal(t, pb.StreamEventType_CLIENT_TIMEOUT, (<-ch).Type)
+
+	quiet.enabled = true
+	quiet.revive(now.Add(5 * time.Second))
+	checkClients(6, stream, now.Add(6*time.Second))
+	require.True(t, quiet.enabled, "revived client has a new grace period")
+}
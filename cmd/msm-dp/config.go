@@ -0,0 +1,314 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// configSettings maps the settings of the config file, by section, to the
+// flags they set. Lists are set as comma separated values.
+var configSettings = map[string]map[string]string{
+	"listen": {
+		"address":     "listenAddress",
+		"controlPort": "port",
+		"rtpPort":     "rtpPort",
+	},
+	"log": {
+		"level":  "logLevel",
+		"format": "logFormat",
+	},
+	"tls": {
+		"cert":           "tlsCert",
+		"key":            "tlsKey",
+		"ca":             "tlsCA",
+		"reloadInterval": "tlsReloadInterval",
+	},
+	"limits": {
+		"maxSourcePps":  "maxSourcePps",
+		"maxEgressKbps": "maxEgressKbps",
+		"drainPeriod":   "drainPeriod",
+	},
+	"destinations": {
+		"allowed":        "allowedDestinations",
+		"ports":          "allowedPorts",
+		"allowLoopback":  "allowLoopback",
+		"allowMulticast": "allowMulticast",
+		"allowBroadcast": "allowBroadcast",
+	},
+	"qos": {
+		"videoDSCP": "videoDSCP",
+		"audioDSCP": "audioDSCP",
+	},
+	"features": {
+		"strictIngress": "strictIngress",
+		"authzPolicy":   "authzPolicy",
+		"stateFile":     "stateFile",
+		"handoffSocket": "handoffSocket",
+	},
+}
+
+// reloadableFlags are the flags whose changes are applied when the config
+// file is reloaded, the others are read at startup only.
+var reloadableFlags = map[string]bool{
+	"logLevel":            true,
+	"logFormat":           true,
+	"maxSourcePps":        true,
+	"maxEgressKbps":       true,
+	"allowedDestinations": true,
+	"allowedPorts":        true,
+	"allowLoopback":       true,
+	"allowMulticast":      true,
+	"allowBroadcast":      true,
+	"videoDSCP":           true,
+	"audioDSCP":           true,
+	"strictIngress":       true,
+}
+
+// commandLineFlags are the flags set on the command line, which the config
+// file doesn't change.
+var commandLineFlags = make(map[string]bool)
+
+// setting is a setting of the config file.
+type setting struct {
+	// key is the section and name of the setting, such as limits.maxSourcePps
+	key   string
+	flag  string
+	value string
+}
+
+// readConfig returns the settings of the config file, which must all be known.
+func readConfig(file string) ([]setting, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var sections map[string]map[string]any
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", file, err)
+	}
+	var settings []setting
+	for section, values := range sections {
+		flags, ok := configSettings[section]
+		if !ok {
+			return nil, fmt.Errorf("unknown section %q in config %s", section, file)
+		}
+		for name, value := range values {
+			key := section + "." + name
+			flagName, ok := flags[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown setting %q in config %s", key, file)
+			}
+			s, err := settingValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			settings = append(settings, setting{key: key, flag: flagName, value: s})
+		}
+	}
+	return settings, nil
+}
+
+func settingValue(value any) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case map[string]any:
+		return "", errors.New("unexpected section")
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			switch item.(type) {
+			case map[string]any, []any:
+				return "", errors.New("lists can only hold values")
+			}
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// loadConfig sets the flags that aren't set on the command line from the
+// config file.
+func loadConfig(file string) error {
+	flag.Visit(func(f *flag.Flag) {
+		commandLineFlags[f.Name] = true
+	})
+	settings, err := readConfig(file)
+	if err != nil {
+		return err
+	}
+	for _, s := range settings {
+		if commandLineFlags[s.flag] {
+			continue
+		}
+		// flag.Set would mark the flag as set on the command line
+		if err := flag.Lookup(s.flag).Value.Set(s.value); err != nil {
+			return fmt.Errorf("invalid %s: %w", s.key, err)
+		}
+	}
+	return nil
+}
+
+// reloadConfig applies the changes of the config file to the reloadable
+// flags, and leaves the flags unchanged if any setting is invalid. Settings
+// removed from the file go back to their defaults.
+func reloadConfig(file string) error {
+	settings, err := readConfig(file)
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]string)
+	values := make(map[string]string)
+	for section, flags := range configSettings {
+		for name, flagName := range flags {
+			keys[flagName] = section + "." + name
+			values[flagName] = flag.Lookup(flagName).DefValue
+		}
+	}
+	for _, s := range settings {
+		values[s.flag] = s.value
+	}
+
+	changes := make(map[string]string)
+	for flagName, value := range values {
+		if commandLineFlags[flagName] {
+			continue
+		}
+		f := flag.Lookup(flagName)
+		value, err := normalize(f, value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", keys[flagName], err)
+		}
+		if value == f.Value.String() {
+			continue
+		}
+		if !reloadableFlags[flagName] {
+			log.Warnf("Setting %s changes on restart", keys[flagName])
+			continue
+		}
+		changes[flagName] = value
+	}
+	if len(changes) == 0 {
+		log.Infof("Reloaded config %s, nothing changed", file)
+		return nil
+	}
+
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	previous := make(map[string]string)
+	for flagName, value := range changes {
+		f := flag.Lookup(flagName)
+		previous[flagName] = f.Value.String()
+		// normalized values are valid
+		_ = f.Value.Set(value)
+	}
+	if err := applySettings(); err != nil {
+		for flagName, value := range previous {
+			_ = flag.Lookup(flagName).Value.Set(value)
+		}
+		return err
+	}
+	changed := make([]string, 0, len(changes))
+	for flagName := range changes {
+		changed = append(changed, keys[flagName])
+	}
+	slices.Sort(changed)
+	log.Infof("Reloaded config %s, changed %s", file, strings.Join(changed, ", "))
+	return nil
+}
+
+// normalize parses a value of the flag without setting the flag, and returns
+// it as the flag formats its values.
+func normalize(f *flag.Flag, value string) (string, error) {
+	scratch := flag.NewFlagSet(f.Name, flag.ContinueOnError)
+	switch f.Value.(flag.Getter).Get().(type) {
+	case bool:
+		scratch.Bool(f.Name, false, "")
+	case int:
+		scratch.Int(f.Name, 0, "")
+	case uint:
+		scratch.Uint(f.Name, 0, "")
+	case time.Duration:
+		scratch.Duration(f.Name, 0, "")
+	default:
+		scratch.String(f.Name, "", "")
+	}
+	if err := scratch.Set(f.Name, value); err != nil {
+		return "", err
+	}
+	return scratch.Lookup(f.Name).Value.String(), nil
+}
+
+// applySettings validates the flags and applies them to the state they
+// configure, which is left unchanged if they are invalid.
+// Must be called with streamsLock held once the streams are forwarded.
+func applySettings() error {
+	if *listenAddress != "" && net.ParseIP(*listenAddress) == nil {
+		return fmt.Errorf("invalid listen address %q", *listenAddress)
+	}
+	if *videoDSCP < unmarked || *videoDSCP > maxDSCP || *audioDSCP < unmarked || *audioDSCP > maxDSCP {
+		return fmt.Errorf("DSCP must be between 0 and %d, or %d to leave packets unmarked", maxDSCP, unmarked)
+	}
+	// invalid log settings fall back to the defaults rather than failing, so
+	// that the data plane is never left without logs
+	var warnings []string
+	level := log.DebugLevel
+	if *logLevel != "" {
+		parsed, err := log.ParseLevel(*logLevel)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Invalid log level %q, logging at debug level", *logLevel))
+		} else {
+			level = parsed
+		}
+	}
+	var formatter log.Formatter = &log.TextFormatter{
+		ForceColors:     true,
+		DisableColors:   false,
+		FullTimestamp:   true,
+		TimestampFormat: "01-02-2006 15:04:05",
+	}
+	switch strings.ToLower(*logFormat) {
+	case "json":
+		formatter = &log.JSONFormatter{
+			TimestampFormat: "2006-01-02 15:04:05",
+			PrettyPrint:     true,
+		}
+	case "", "text":
+	default:
+		warnings = append(warnings, fmt.Sprintf("Invalid log format %q, logging text", *logFormat))
+	}
+	if err := parseDestinationFlags(); err != nil {
+		return fmt.Errorf("invalid allowed destinations: %w", err)
+	}
+	log.SetLevel(level)
+	log.SetFormatter(formatter)
+	for _, warning := range warnings {
+		log.Warn(warning)
+	}
+	applyIngressFlags()
+	return nil
+}
+
+// watchConfig reloads the config file on SIGHUP.
+func watchConfig(file string) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	for range hangups {
+		if err := reloadConfig(file); err != nil {
+			log.WithError(err).Errorf("Could not reload config %s, settings unchanged", file)
+		}
+	}
+}
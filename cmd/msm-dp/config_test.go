@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// restoreSettings restores the configurable flags and the state they
+// configure when the test ends.
+func restoreSettings(t *testing.T) {
+	values := make(map[string]string)
+	for _, flags := range configSettings {
+		for _, flagName := range flags {
+			values[flagName] = flag.Lookup(flagName).Value.String()
+		}
+	}
+	t.Cleanup(func() {
+		for flagName, value := range values {
+			require.NoError(t, flag.Lookup(flagName).Value.Set(value))
+		}
+		commandLineFlags = make(map[string]bool)
+		require.NoError(t, applySettings())
+	})
+}
+
+func writeConfig(t *testing.T, file, content string) {
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+}
+
+func TestReadConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, file, `{"destinations": {"allowed": ["10.0.0.0/8", "2001:db8::/32"], "allowLoopback": true}, "limits": {"maxSourcePps": 1000}}`)
+	settings, err := readConfig(file)
+	require.NoError(t, err, "JSON is YAML")
+	require.ElementsMatch(t, []setting{
+		{key: "destinations.allowed", flag: "allowedDestinations", value: "10.0.0.0/8,2001:db8::/32"},
+		{key: "destinations.allowLoopback", flag: "allowLoopback", value: "true"},
+		{key: "limits.maxSourcePps", flag: "maxSourcePps", value: "1000"},
+	}, settings)
+
+	for _, content := range []string{
+		"listen:\n  addresses: 10.0.0.1\n",
+		"unknown:\n  port: 1\n",
+		"qos:\n  videoDSCP:\n    value: 1\n",
+		"destinations:\n  allowed:\n    - [10.0.0.0/8]\n",
+		"qos: [1]\n",
+	} {
+		writeConfig(t, file, content)
+		_, err := readConfig(file)
+		require.Error(t, err, content)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	restoreSettings(t)
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, file, `
+log:
+  level: info
+qos:
+  videoDSCP: 40
+destinations:
+  allowed:
+    - 10.0.0.0/8
+limits:
+  maxSourcePps: 100
+  maxEgressKbps: 1000
+features:
+  strictIngress: true
+`)
+	require.NoError(t, loadConfig(file))
+	require.NoError(t, applySettings())
+	require.Equal(t, 40, *videoDSCP)
+	require.Len(t, allowedDestinations, 1)
+	require.EqualValues(t, 100, ingress.Load().limiter.pps)
+	require.True(t, ingress.Load().strict)
+	limiter := ingress.Load().limiter
+	egress := nodeEgress
+	require.NotNil(t, egress)
+
+	writeConfig(t, file, `
+log:
+  level: info
+qos:
+  videoDSCP: 30
+listen:
+  rtpPort: 9999
+limits:
+  maxSourcePps: 100
+  maxEgressKbps: 1000
+`)
+	require.NoError(t, reloadConfig(file))
+	require.Equal(t, 30, *videoDSCP)
+	require.Empty(t, allowedDestinations, "removed settings go back to their defaults")
+	require.False(t, ingress.Load().strict)
+	require.Same(t, limiter, ingress.Load().limiter, "unchanged limits keep the senders' rates")
+	require.Same(t, egress, nodeEgress, "an unchanged node cap keeps its tokens")
+	require.Equal(t, 8050, *rtpPort, "the ports change on restart")
+
+	writeConfig(t, file, "qos:\n  videoDSCP: 20\ndestinations:\n  allowed: [bogus]\n")
+	require.Error(t, reloadConfig(file))
+	require.Equal(t, 30, *videoDSCP, "invalid configs change nothing")
+	writeConfig(t, file, "qos:\n  videoDSCP: 64\n")
+	require.Error(t, reloadConfig(file))
+	require.Equal(t, 30, *videoDSCP)
+	writeConfig(t, file, "limits:\n  maxSourcePps: -1\n")
+	require.Error(t, reloadConfig(file))
+	require.EqualValues(t, 100, ingress.Load().limiter.pps)
+
+	writeConfig(t, file, "log:\n  level: loud\n  format: xml\nqos:\n  videoDSCP: 30\n")
+	require.NoError(t, reloadConfig(file), "invalid log settings fall back to the defaults")
+	require.Equal(t, log.DebugLevel, log.GetLevel())
+	require.IsType(t, &log.TextFormatter{}, log.StandardLogger().Formatter)
+	require.Nil(t, nodeEgress)
+
+	commandLineFlags["videoDSCP"] = true
+	writeConfig(t, file, "qos:\n  videoDSCP: 10\n")
+	require.NoError(t, reloadConfig(file))
+	require.Equal(t, 30, *videoDSCP, "flags set on the command line take precedence")
+}
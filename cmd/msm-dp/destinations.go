@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// allowedDestinations and allowedPorts restrict the clients when not empty
+	allowedDestinations []netip.Prefix
+	allowedPorts        [][2]int
+	// nodeEgress caps the RTP packets sent to all clients at nodeEgressKbps, if set
+	nodeEgress     *tokenBucket
+	nodeEgressKbps uint32
+)
+
+// parseDestinationFlags sets the destination restrictions from the flags,
+// and leaves them unchanged if the flags are invalid.
+// Must be called with streamsLock held once the streams are forwarded.
+func parseDestinationFlags() error {
+	var destinations []netip.Prefix
+	for _, cidr := range strings.Split(*allowedDestinationsFlag, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return err
+		}
+		destinations = append(destinations, prefix.Masked())
+	}
+	var portRanges [][2]int
+	for _, ports := range strings.Split(*allowedPortsFlag, ",") {
+		if ports = strings.TrimSpace(ports); ports == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(ports, "-")
+		low, err := strconv.ParseUint(first, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid port %q", ports)
+		}
+		high := low
+		if isRange {
+			if high, err = strconv.ParseUint(last, 10, 16); err != nil || high < low {
+				return fmt.Errorf("invalid port range %q", ports)
+			}
+		}
+		portRanges = append(portRanges, [2]int{int(low), int(high)})
+	}
+	allowedDestinations, allowedPorts = destinations, portRanges
+	// the node's cap keeps its tokens unless its rate changes
+	if kbps := uint32(*maxEgressKbps); kbps != nodeEgressKbps {
+		nodeEgress, nodeEgressKbps = nil, kbps
+		if kbps > 0 {
+			nodeEgress = newTokenBucket(kbps, capBurst, time.Now())
+		}
+	}
+	return nil
+}
+
+// checkDestination returns why packets can't be sent to a client's RTP and
+// RTCP ports, or nil if they can.
+func checkDestination(address net.UDPAddr) error {
+	addr, ok := netip.AddrFromSlice(address.IP)
+	if !ok {
+		return fmt.Errorf("invalid destination %v", address.IP)
+	}
+	addr = addr.Unmap()
+	switch {
+	case addr.IsUnspecified():
+		return fmt.Errorf("unspecified destination %v", addr)
+	case addr.IsLoopback() && !*allowLoopback:
+		return fmt.Errorf("loopback destination %v not allowed", addr)
+	case addr.IsMulticast() && !*allowMulticast:
+		return fmt.Errorf("multicast destination %v not allowed", addr)
+	case isBroadcast(addr) && !*allowBroadcast:
+		return fmt.Errorf("broadcast destination %v not allowed", addr)
+	}
+	if len(allowedDestinations) > 0 && !slices.ContainsFunc(allowedDestinations, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	}) {
+		return fmt.Errorf("destination %v not in the allowed CIDRs", addr)
+	}
+	if len(allowedPorts) > 0 {
+		for _, port := range []int{address.Port, address.Port + 1} {
+			if !slices.ContainsFunc(allowedPorts, func(ports [2]int) bool {
+				return port >= ports[0] && port <= ports[1]
+			}) {
+				return fmt.Errorf("destination port %d not allowed", port)
+			}
+		}
+	}
+	return nil
+}
+
+// isBroadcast reports whether the address is the limited broadcast address or
+// the broadcast address of one of the node's IPv4 subnets.
+func isBroadcast(addr netip.Addr) bool {
+	if !addr.Is4() {
+		return false
+	}
+	if addr == netip.AddrFrom4([4]byte{255, 255, 255, 255}) {
+		return true
+	}
+	interfaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, interfaceAddr := range interfaceAddrs {
+		ipNet, ok := interfaceAddr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() == nil {
+			continue
+		}
+		ones, bits := ipNet.Mask.Size()
+		if bits != 32 || ones >= 31 {
+			// point to point subnets have no broadcast address
+			continue
+		}
+		broadcast := make(net.IP, 4)
+		for i, b := range ipNet.IP.To4() {
+			broadcast[i] = b | ^ipNet.Mask[i]
+		}
+		if broadcast.Equal(net.IP(addr.AsSlice())) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestMain(m *testing.M) {
+	// the tests send to loopback and multicast clients
+	*allowLoopback = true
+	*allowMulticast = true
+	os.Exit(m.Run())
+}
+
+// restrictDestinations applies the default destination restrictions until the test ends.
+func restrictDestinations(t *testing.T) {
+	*allowLoopback, *allowMulticast = false, false
+	t.Cleanup(func() {
+		*allowLoopback, *allowMulticast = true, true
+		allowedDestinations, allowedPorts = nil, nil
+	})
+}
+
+func TestCheckDestination(t *testing.T) {
+	restrictDestinations(t)
+	for _, test := range []struct {
+		address string
+		allowed bool
+	}{
+		{"10.1.2.3:5000", true},
+		{"[2001:db8::1]:5000", true},
+		{"127.0.0.1:5000", false},
+		{"[::1]:5000", false},
+		{"[::ffff:127.0.0.1]:5000", false},
+		{"239.1.2.3:5000", false},
+		{"[ff0e::1]:5000", false},
+		{"255.255.255.255:5000", false},
+		{"0.0.0.0:5000", false},
+	} {
+		address, err := net.ResolveUDPAddr("udp", test.address)
+		require.NoError(t, err)
+		require.Equal(t, test.allowed, checkDestination(*address) == nil, test.address)
+	}
+
+	*allowedDestinationsFlag, *allowedPortsFlag = "10.0.0.0/8, 2001:db8::/32", "5000-5001,6000-6009"
+	defer func() { *allowedDestinationsFlag, *allowedPortsFlag = "", "" }()
+	require.NoError(t, parseDestinationFlags())
+	require.NoError(t, checkDestination(net.UDPAddr{IP: net.IPv4(10, 1, 2, 3), Port: 5000}))
+	require.NoError(t, checkDestination(net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 6008}))
+	require.Error(t, checkDestination(net.UDPAddr{IP: net.IPv4(192, 168, 1, 1), Port: 5000}))
+	require.Error(t, checkDestination(net.UDPAddr{IP: net.IPv4(10, 1, 2, 3), Port: 5001}), "the RTCP port must be allowed too")
+	require.Error(t, checkDestination(net.UDPAddr{IP: net.IPv4(10, 1, 2, 3), Port: 7000}))
+
+	*allowedPortsFlag = "6000-5000"
+	require.Error(t, parseDestinationFlags())
+}
+
+func TestBroadcastDestination(t *testing.T) {
+	interfaceAddrs, err := net.InterfaceAddrs()
+	require.NoError(t, err)
+	for _, interfaceAddr := range interfaceAddrs {
+		ipNet, ok := interfaceAddr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() == nil || ipNet.IP.IsLoopback() {
+			continue
+		}
+		if ones, _ := ipNet.Mask.Size(); ones >= 31 {
+			continue
+		}
+		broadcast := make(net.IP, 4)
+		for i, b := range ipNet.IP.To4() {
+			broadcast[i] = b | ^ipNet.Mask[i]
+		}
+		addr, _ := netip.AddrFromSlice(broadcast)
+		require.True(t, isBroadcast(addr), "broadcast address of %v", ipNet)
+		require.False(t, isBroadcast(addr.Prev()))
+		return
+	}
+	t.Skip("no IPv4 subnet with a broadcast address")
+}
+
+func TestRejectedClients(t *testing.T) {
+	s := &server{}
+	_, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 16, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7900},
+	})
+	require.NoError(t, err)
+	defer s.StreamAddDel(context.Background(), &pb.StreamData{Id: 16, Operation: pb.StreamOperation_DELETE})
+	restrictDestinations(t)
+
+	result, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 16, Operation: pb.StreamOperation_ADD_EP, Enable: true, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7902},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.ErrorMessage)
+
+	// a latched client is checked against the address it latches to
+	result, err = s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 16, Operation: pb.StreamOperation_ADD_EP, Enable: true, Endpoint: &pb.Endpoint{Ip: "10.1.2.3", Port: 7904},
+		Latch: pb.LatchMode_LATCH_SSRC, LatchSsrc: 0x12345678,
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.ErrorMessage)
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	require.False(t, latchClient(rtpPacket(1, 100), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7904}, false))
+	require.Len(t, streams[16].clients, 1)
+	require.False(t, streams[16].clients[clientKey(&pb.StreamData{
+		Endpoint: &pb.Endpoint{Ip: "10.1.2.3", Port: 7904}, Latch: pb.LatchMode_LATCH_SSRC, LatchSsrc: 0x12345678,
+	})].latch.latched)
+}
+
+func TestNodeEgressCap(t *testing.T) {
+	conn := listenLocalUDP(t)
+	now := time.Now()
+	// 80 kbps allows a burst of 5000 bytes for all clients
+	nodeEgress = newTokenBucket(80, capBurst, now)
+	defer func() { nodeEgress = nil }()
+
+	stream := &Stream{stats: newCounters()}
+	clients := []*Endpoint{
+		{address: net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7910}},
+		{address: net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7912}},
+	}
+	payload := make([]byte, 1000-rtpHeaderLen)
+	for i := 0; i < 3; i++ {
+		for _, client := range clients {
+			client.sendRTP(conn, stream, rtpPacket(uint16(i), 100, payload...), now)
+		}
+	}
+	require.Equal(t, uint32(5), clients[0].packets+clients[1].packets)
+	require.Equal(t, uint64(1), stream.stats.snapshot()["node_capped_drops"])
+}
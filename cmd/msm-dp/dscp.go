@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+const (
+	// maxDSCP is the largest 6-bit differentiated services code point
+	maxDSCP = 63
+	// unmarked leaves the DSCP of the packets to the socket's default
+	unmarked = -1
+)
+
+// streamDSCP returns the DSCP of a stream's egress, from its configuration or
+// the default for its media type.
+func streamDSCP(in *pb.StreamData) (int, error) {
+	if in.Dscp != nil {
+		return requestedDSCP(in.GetDscp())
+	}
+	if in.Media == pb.MediaType_MEDIA_AUDIO {
+		return *audioDSCP, nil
+	}
+	return *videoDSCP, nil
+}
+
+// clientDSCP returns the DSCP of a client's egress, from its configuration or
+// the stream's DSCP.
+func clientDSCP(in *pb.StreamData, stream *Stream) (int, error) {
+	if in.Dscp != nil {
+		return requestedDSCP(in.GetDscp())
+	}
+	return stream.dscp, nil
+}
+
+func requestedDSCP(dscp uint32) (int, error) {
+	if dscp > maxDSCP {
+		return 0, fmt.Errorf("invalid DSCP %d", dscp)
+	}
+	return int(dscp), nil
+}
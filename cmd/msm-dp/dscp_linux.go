@@ -0,0 +1,31 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// trafficClass returns the control message that marks a packet sent to the
+// destination with the DSCP, or nil to send it unmarked with the socket's default.
+func trafficClass(destination net.IP, dscp int) []byte {
+	if dscp == unmarked {
+		return nil
+	}
+	// IPv4 destinations of dual-stack sockets are sent with the IPv4 option too
+	level, typ := syscall.IPPROTO_IP, syscall.IP_TOS
+	if destination.To4() == nil {
+		level, typ = syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS
+	}
+	oob := make([]byte, syscall.CmsgSpace(4))
+	header := (*syscall.Cmsghdr)(unsafe.Pointer(&oob[0]))
+	header.Level = int32(level)
+	header.Type = int32(typ)
+	header.SetLen(syscall.CmsgLen(4))
+	// the DSCP is the upper 6 bits of the ToS or traffic class byte
+	binary.NativeEndian.PutUint32(oob[syscall.CmsgLen(0):], uint32(dscp<<2))
+	return oob
+}
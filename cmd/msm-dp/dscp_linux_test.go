@@ -0,0 +1,70 @@
+//go:build linux
+
+package main
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// receivedTOS reads a packet and returns the ToS or traffic class it was sent with.
+func receivedTOS(t *testing.T, conn *net.UDPConn) int {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buffer := make([]byte, 1500)
+	oob := make([]byte, 128)
+	_, oobn, _, _, err := conn.ReadMsgUDP(buffer, oob)
+	require.NoError(t, err)
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err)
+	for _, m := range messages {
+		if (m.Header.Level == syscall.IPPROTO_IP && m.Header.Type == syscall.IP_TOS) ||
+			(m.Header.Level == syscall.IPPROTO_IPV6 && m.Header.Type == syscall.IPV6_TCLASS) {
+			return int(m.Data[0])
+		}
+	}
+	t.Fatal("no ToS control message")
+	return 0
+}
+
+func TestTrafficClass(t *testing.T) {
+	sender := listenUDP(0, "RTP")
+	defer sender.Close()
+
+	for _, test := range []struct {
+		network string
+		ip      net.IP
+		level   int
+		option  int
+	}{
+		{"udp4", net.IPv4(127, 0, 0, 1), syscall.IPPROTO_IP, syscall.IP_RECVTOS},
+		{"udp6", net.IPv6loopback, syscall.IPPROTO_IPV6, syscall.IPV6_RECVTCLASS},
+	} {
+		receiver, err := net.ListenUDP(test.network, &net.UDPAddr{IP: test.ip})
+		if err != nil {
+			t.Logf("%s not available: %v", test.network, err)
+			continue
+		}
+		defer receiver.Close()
+		rawConn, err := receiver.SyscallConn()
+		require.NoError(t, err)
+		require.NoError(t, rawConn.Control(func(fd uintptr) {
+			require.NoError(t, syscall.SetsockoptInt(int(fd), test.level, test.option, 1))
+		}))
+
+		endpoint := &Endpoint{address: *receiver.LocalAddr().(*net.UDPAddr), dscp: 46}
+		require.NoError(t, endpoint.writeRTP(sender, rtpPacket(1, 100)))
+		require.Equal(t, 46<<2, receivedTOS(t, receiver))
+
+		endpoint.dscp = 0
+		require.NoError(t, endpoint.writeRTP(sender, rtpPacket(2, 100)))
+		require.Equal(t, 0, receivedTOS(t, receiver))
+
+		endpoint.dscp = unmarked
+		require.NoError(t, endpoint.writeRTP(sender, rtpPacket(3, 100)))
+		require.Equal(t, 0, receivedTOS(t, receiver))
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "net"
+
+// trafficClass is only supported on Linux.
+func trafficClass(net.IP, int) []byte {
+	return nil
+}
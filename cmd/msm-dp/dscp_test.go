@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestStreamDSCP(t *testing.T) {
+	dscp, err := streamDSCP(&pb.StreamData{})
+	require.NoError(t, err)
+	require.Equal(t, unmarked, dscp, "not marked unless configured")
+	defer func(video, audio int) { *videoDSCP, *audioDSCP = video, audio }(*videoDSCP, *audioDSCP)
+	*videoDSCP, *audioDSCP = 34, 46
+	dscp, err = streamDSCP(&pb.StreamData{})
+	require.NoError(t, err)
+	require.Equal(t, 34, dscp)
+	dscp, err = streamDSCP(&pb.StreamData{Media: pb.MediaType_MEDIA_AUDIO})
+	require.NoError(t, err)
+	require.Equal(t, 46, dscp)
+	dscp, err = streamDSCP(&pb.StreamData{Media: pb.MediaType_MEDIA_AUDIO, Dscp: proto.Uint32(26)})
+	require.NoError(t, err)
+	require.Equal(t, 26, dscp)
+	dscp, err = streamDSCP(&pb.StreamData{Media: pb.MediaType_MEDIA_AUDIO, Dscp: proto.Uint32(0)})
+	require.NoError(t, err)
+	require.Equal(t, 0, dscp, "CS0 can be requested")
+	_, err = streamDSCP(&pb.StreamData{Dscp: proto.Uint32(64)})
+	require.Error(t, err)
+}
+
+func TestClientDSCP(t *testing.T) {
+	s := &server{}
+	_, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 14, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7600}, Dscp: proto.Uint32(40),
+	})
+	require.NoError(t, err)
+	defer s.StreamAddDel(context.Background(), &pb.StreamData{Id: 14, Operation: pb.StreamOperation_DELETE})
+
+	for _, client := range []*pb.StreamData{
+		{Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7602}},
+		{Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7604}, Dscp: proto.Uint32(10)},
+		{Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7608}, Dscp: proto.Uint32(0)},
+	} {
+		client.Id, client.Operation, client.Enable = 14, pb.StreamOperation_ADD_EP, true
+		result, err := s.StreamAddDel(context.Background(), client)
+		require.NoError(t, err)
+		require.Empty(t, result.ErrorMessage)
+	}
+	result, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 14, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7606}, Dscp: proto.Uint32(100),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.ErrorMessage)
+
+	streamsLock.RLock()
+	defer streamsLock.RUnlock()
+	require.Equal(t, 40, streams[14].clients["127.0.0.1:7602"].dscp, "clients default to the stream's DSCP")
+	require.Equal(t, 10, streams[14].clients["127.0.0.1:7604"].dscp)
+	require.Equal(t, 0, streams[14].clients["127.0.0.1:7608"].dscp)
+	require.NotContains(t, streams[14].clients, "127.0.0.1:7606")
+}
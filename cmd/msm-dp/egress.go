@@ -0,0 +1,77 @@
+package main
+
+import "net"
+
+// rtcpAddress returns the address of the client's RTCP port, the one it
+// latched or next to its RTP port.
+func (e *Endpoint) rtcpAddress() net.UDPAddr {
+	if e.rtcp != nil {
+		return *e.rtcp
+	}
+	return net.UDPAddr{IP: e.address.IP, Port: e.address.Port + 1, Zone: e.address.Zone}
+}
+
+// rtpEgress returns the socket that RTP packets are sent to the client from,
+// the shared RTP socket unless the client has its own sockets.
+func (e *Endpoint) rtpEgress(rtpConn *net.UDPConn) *net.UDPConn {
+	if e.multicast != nil {
+		return e.multicast.rtp
+	}
+	return rtpConn
+}
+
+// writeRTP sends an RTP packet to the client.
+func (e *Endpoint) writeRTP(rtpConn *net.UDPConn, packet []byte) error {
+	if e.srtp != nil {
+		var err error
+		if packet, err = e.protectRTP(packet); err != nil {
+			return err
+		}
+	}
+	return e.transmitRTP(rtpConn, packet)
+}
+
+// protectRTP returns the SRTP packet of an RTP packet sent to the client, and
+// keeps it to be retransmitted.
+func (e *Endpoint) protectRTP(packet []byte) ([]byte, error) {
+	protected, err := e.srtp.protectRTP(packet)
+	if err == nil && e.srtpSent != nil {
+		e.srtpSent.store(protected)
+	}
+	return protected, err
+}
+
+// transmitRTP sends an RTP or SRTP packet to the client as is.
+func (e *Endpoint) transmitRTP(rtpConn *net.UDPConn, packet []byte) error {
+	_, _, err := e.rtpEgress(rtpConn).WriteMsgUDP(packet, trafficClass(e.address.IP, e.dscp), &e.address)
+	return err
+}
+
+// writeRTCP sends an RTCP packet to the client, from the shared RTCP socket
+// unless the client has its own sockets.
+func (e *Endpoint) writeRTCP(rtcpConn *net.UDPConn, packet []byte) error {
+	if e.multicast != nil {
+		rtcpConn = e.multicast.rtcp
+	}
+	if e.srtp != nil {
+		var err error
+		if packet, err = e.srtp.protectRTCP(packet); err != nil {
+			return err
+		}
+	}
+	address := e.rtcpAddress()
+	_, _, err := rtcpConn.WriteMsgUDP(packet, trafficClass(address.IP, e.dscp), &address)
+	return err
+}
+
+// close releases the client's own sockets and pacer, if any.
+func (e *Endpoint) close() {
+	if e.pacer != nil {
+		e.pacer.stop()
+		e.pacer = nil
+	}
+	if e.multicast != nil {
+		e.multicast.close()
+		e.multicast = nil
+	}
+}
@@ -0,0 +1,88 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+)
+
+// enableErrorQueue asks the kernel to queue ICMP errors for the socket, so
+// that unreachable destinations can be found with readErrorQueue.
+func enableErrorQueue(conn *net.UDPConn) error {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		// the socket may be IPv4 only or dual-stack, so try both options
+		errV4 := syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_RECVERR, 1)
+		errV6 := syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_RECVERR, 1)
+		if errV4 != nil && errV6 != nil {
+			sockErr = errV4
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
+
+// readErrorQueue drains the socket's error queue and returns the destinations
+// that were reported unreachable.
+func readErrorQueue(conn *net.UDPConn) []net.UDPAddr {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return nil
+	}
+	var unreachable []net.UDPAddr
+	buffer := make([]byte, 1500)
+	oob := make([]byte, 512)
+	_ = rawConn.Read(func(fd uintptr) bool {
+		for {
+			_, oobn, _, from, err := syscall.Recvmsg(int(fd), buffer, oob, syscall.MSG_ERRQUEUE|syscall.MSG_DONTWAIT)
+			if err != nil {
+				// the queue is empty
+				return true
+			}
+			if !isUnreachable(oob[:oobn]) {
+				continue
+			}
+			switch sa := from.(type) {
+			case *syscall.SockaddrInet4:
+				unreachable = append(unreachable, net.UDPAddr{IP: net.IP(sa.Addr[:]).To4(), Port: sa.Port})
+			case *syscall.SockaddrInet6:
+				ip := net.IP(append([]byte(nil), sa.Addr[:]...))
+				if ip4 := ip.To4(); ip4 != nil {
+					ip = ip4
+				}
+				unreachable = append(unreachable, net.UDPAddr{IP: ip, Port: sa.Port})
+			}
+		}
+	})
+	return unreachable
+}
+
+// isUnreachable reports whether the control messages of an error queue
+// message carry a destination or port unreachable error.
+func isUnreachable(oob []byte) bool {
+	messages, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return false
+	}
+	for _, m := range messages {
+		isErr := (m.Header.Level == syscall.IPPROTO_IP && m.Header.Type == syscall.IP_RECVERR) ||
+			(m.Header.Level == syscall.IPPROTO_IPV6 && m.Header.Type == syscall.IPV6_RECVERR)
+		if !isErr || len(m.Data) < 4 {
+			continue
+		}
+		// struct sock_extended_err starts with ee_errno
+		switch syscall.Errno(binary.NativeEndian.Uint32(m.Data[0:4])) {
+		case syscall.ECONNREFUSED, syscall.EHOSTUNREACH, syscall.ENETUNREACH:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientUnreachable(t *testing.T) {
+	conn := listenLocalUDP(t)
+	require.NoError(t, enableErrorQueue(conn))
+
+	// find a port nobody listens on
+	closed := listenLocalUDP(t)
+	clientAddr := *closed.LocalAddr().(*net.UDPAddr)
+	require.NoError(t, closed.Close())
+
+	endpoint := &Endpoint{enabled: true, address: clientAddr, added: time.Now()}
+	stream := &Stream{clients: map[string]*Endpoint{clientAddr.String(): endpoint}, disableDeadClients: true, stats: newCounters()}
+	streamsLock.Lock()
+	streams[7] = stream
+	streamsLock.Unlock()
+	defer func() {
+		streamsLock.Lock()
+		delete(streams, 7)
+		streamsLock.Unlock()
+	}()
+
+	var unreachable []net.UDPAddr
+	for i := 0; i < unreachableThreshold; i++ {
+		_, err := conn.WriteToUDP(rtpPacket(uint16(i), 0), &clientAddr)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			unreachable = readErrorQueue(conn)
+			return len(unreachable) > 0
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, clientAddr.Port, unreachable[0].Port)
+		require.True(t, clientAddr.IP.Equal(unreachable[0].IP))
+
+		streamsLock.Lock()
+		clientUnreachable(unreachable[0])
+		streamsLock.Unlock()
+	}
+
+	require.True(t, endpoint.dead)
+	require.False(t, endpoint.enabled)
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package main
+
+import "net"
+
+// enableErrorQueue is only supported on Linux.
+func enableErrorQueue(*net.UDPConn) error {
+	return nil
+}
+
+// readErrorQueue is only supported on Linux.
+func readErrorQueue(*net.UDPConn) []net.UDPAddr {
+	return nil
+}
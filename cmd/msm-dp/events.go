@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net"
+	"sync"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// eventQueueLength is the number of events buffered for each subscriber
+// before further events are dropped.
+const eventQueueLength = 64
+
+// eventBroker distributes stream events to the subscribed controllers.
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan *pb.StreamEvent]struct{}
+}
+
+var events = newEventBroker()
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{subscribers: make(map[chan *pb.StreamEvent]struct{})}
+}
+
+func (b *eventBroker) subscribe() chan *pb.StreamEvent {
+	ch := make(chan *pb.StreamEvent, eventQueueLength)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *eventBroker) unsubscribe(ch chan *pb.StreamEvent) {
+	b.mu.Lock()
+	delete(b.subscribers, ch)
+	b.mu.Unlock()
+}
+
+// publish sends an event to all subscribers without blocking the caller.
+func (b *eventBroker) publish(event *pb.StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Warnf("Event queue full, dropping %v event for stream %v", event.Type, event.Id)
+		}
+	}
+}
+
+func endpointEvent(streamID uint32, eventType pb.StreamEventType, address net.UDPAddr, message string) *pb.StreamEvent {
+	return &pb.StreamEvent{
+		Id:       streamID,
+		Type:     eventType,
+		Endpoint: &pb.Endpoint{Ip: address.IP.String(), Port: uint32(address.Port)},
+		Message:  message,
+	}
+}
+
+func (s *server) StreamEvents(_ *pb.StreamEventsRequest, stream pb.MsmDataPlane_StreamEventsServer) error {
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+	log.Info("Controller subscribed to stream events")
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			log.Info("Controller unsubscribed from stream events")
+			return nil
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "msm-dp is shutting down")
+		case event := <-ch:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/binary"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+// maxGOPPackets bounds the memory used by a single stream's keyframe cache.
+// A GOP larger than this is not cached and late joiners wait for the next keyframe.
+const maxGOPPackets = 4096
+
+// maxParamPackets bounds the number of parameter set packets kept for the next keyframe.
+const maxParamPackets = 16
+
+// gopCache keeps the RTP packets since the last keyframe so that they can be
+// replayed to a client that joins mid-stream.
+type gopCache struct {
+	codec     pb.VideoCodec
+	valid     bool
+	timestamp uint32
+	params    [][]byte
+	packets   [][]byte
+}
+
+func newGOPCache(codec pb.VideoCodec) *gopCache {
+	return &gopCache{codec: codec}
+}
+
+// add records an RTP packet and reports whether it is part of the cached GOP.
+func (c *gopCache) add(packet []byte) bool {
+	payload, ok := rtpPayload(packet)
+	if !ok || len(payload) == 0 {
+		return c.append(packet)
+	}
+	keyframe, params := classifyNALs(c.codec, payload)
+	timestamp := rtpTimestamp(packet)
+
+	switch {
+	case keyframe && (!c.valid || timestamp != c.timestamp):
+		// first packet of a new keyframe, start a new GOP with the latest parameter sets
+		c.packets = append(c.params, clonePacket(packet))
+		c.params = nil
+		c.timestamp = timestamp
+		c.valid = true
+		return true
+	case params:
+		if len(c.params) == maxParamPackets {
+			c.params = c.params[1:]
+		}
+		c.params = append(c.params, clonePacket(packet))
+	}
+	return c.append(packet)
+}
+
+func (c *gopCache) append(packet []byte) bool {
+	if !c.valid {
+		return false
+	}
+	if len(c.packets) == maxGOPPackets {
+		c.valid = false
+		c.packets = nil
+		return false
+	}
+	c.packets = append(c.packets, clonePacket(packet))
+	return true
+}
+
+// reset drops the cached GOP, e.g. when the stream switches to another source.
+func (c *gopCache) reset() {
+	c.valid = false
+	c.packets = nil
+	c.params = nil
+}
+
+func clonePacket(packet []byte) []byte {
+	return append([]byte(nil), packet...)
+}
+
+// classifyNALs inspects the NAL unit header(s) in an RTP payload and reports
+// whether it starts a keyframe and whether it carries parameter sets.
+func classifyNALs(codec pb.VideoCodec, payload []byte) (keyframe, params bool) {
+	switch codec {
+	case pb.VideoCodec_H264:
+		return classifyH264(payload)
+	case pb.VideoCodec_H265:
+		return classifyH265(payload)
+	default:
+		return false, false
+	}
+}
+
+// classifyH264 follows the packetization modes in RFC 6184.
+func classifyH264(payload []byte) (keyframe, params bool) {
+	check := func(nalType byte) {
+		switch nalType {
+		case 5:
+			keyframe = true
+		case 7, 8:
+			params = true
+		}
+	}
+
+	switch nalType := payload[0] & 0x1f; nalType {
+	case 24, 25: // STAP-A, STAP-B
+		offset := 1
+		if nalType == 25 {
+			offset += 2
+		}
+		for offset+2 < len(payload) {
+			size := int(binary.BigEndian.Uint16(payload[offset:]))
+			offset += 2
+			if size == 0 || offset+size > len(payload) {
+				break
+			}
+			check(payload[offset] & 0x1f)
+			offset += size
+		}
+	case 28, 29: // FU-A, FU-B
+		if len(payload) > 1 && payload[1]&0x80 != 0 {
+			check(payload[1] & 0x1f)
+		}
+	default:
+		check(nalType)
+	}
+	return keyframe, params
+}
+
+// classifyH265 follows the packetization in RFC 7798, without DONL fields.
+func classifyH265(payload []byte) (keyframe, params bool) {
+	if len(payload) < 2 {
+		return false, false
+	}
+	check := func(nalType byte) {
+		switch {
+		case nalType >= 16 && nalType <= 21:
+			keyframe = true
+		case nalType >= 32 && nalType <= 34:
+			params = true
+		}
+	}
+
+	switch nalType := payload[0] >> 1 & 0x3f; nalType {
+	case 48: // aggregation packet
+		offset := 2
+		for offset+2 < len(payload) {
+			size := int(binary.BigEndian.Uint16(payload[offset:]))
+			offset += 2
+			if size == 0 || offset+size > len(payload) {
+				break
+			}
+			check(payload[offset] >> 1 & 0x3f)
+			offset += size
+		}
+	case 49: // fragmentation unit
+		if len(payload) > 2 && payload[2]&0x80 != 0 {
+			check(payload[2] & 0x3f)
+		}
+	default:
+		check(nalType)
+	}
+	return keyframe, params
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func rtpPacket(seq uint16, timestamp uint32, payload ...byte) []byte {
+	packet := make([]byte, rtpHeaderLen, rtpHeaderLen+len(payload))
+	packet[0] = 0x80
+	packet[1] = 96
+	binary.BigEndian.PutUint16(packet[2:], seq)
+	binary.BigEndian.PutUint32(packet[4:], timestamp)
+	binary.BigEndian.PutUint32(packet[8:], 0x12345678)
+	return append(packet, payload...)
+}
+
+func TestClassifyH264(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  []byte
+		keyframe bool
+		params   bool
+	}{
+		{"IDR", []byte{0x65, 0x88}, true, false},
+		{"non-IDR slice", []byte{0x41, 0x9a}, false, false},
+		{"SPS", []byte{0x67, 0x42}, false, true},
+		{"STAP-A with SPS, PPS and IDR", []byte{0x78, 0x00, 0x02, 0x67, 0x42, 0x00, 0x02, 0x68, 0xce, 0x00, 0x02, 0x65, 0x88}, true, true},
+		{"FU-A start of IDR", []byte{0x7c, 0x85, 0x88}, true, false},
+		{"FU-A continuation of IDR", []byte{0x7c, 0x05, 0x88}, false, false},
+	}
+	for _, test := range tests {
+		keyframe, params := classifyNALs(pb.VideoCodec_H264, test.payload)
+		require.Equal(t, test.keyframe, keyframe, test.name)
+		require.Equal(t, test.params, params, test.name)
+	}
+}
+
+func TestClassifyH265(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  []byte
+		keyframe bool
+		params   bool
+	}{
+		{"IDR_W_RADL", []byte{0x26, 0x01, 0xaf}, true, false},
+		{"TRAIL_R", []byte{0x02, 0x01, 0xd0}, false, false},
+		{"VPS", []byte{0x40, 0x01, 0x0c}, false, true},
+		{"AP with SPS and CRA", []byte{0x60, 0x01, 0x00, 0x02, 0x42, 0x01, 0x00, 0x02, 0x2a, 0x01}, true, true},
+		{"FU start of IDR_N_LP", []byte{0x62, 0x01, 0x94, 0xaf}, true, false},
+		{"FU end of IDR_N_LP", []byte{0x62, 0x01, 0x54, 0xaf}, false, false},
+	}
+	for _, test := range tests {
+		keyframe, params := classifyNALs(pb.VideoCodec_H265, test.payload)
+		require.Equal(t, test.keyframe, keyframe, test.name)
+		require.Equal(t, test.params, params, test.name)
+	}
+}
+
+func TestGOPCache(t *testing.T) {
+	cache := newGOPCache(pb.VideoCodec_H264)
+
+	require.False(t, cache.add(rtpPacket(1, 1000, 0x41, 0x9a)), "nothing cached before the first keyframe")
+	require.False(t, cache.add(rtpPacket(2, 2000, 0x67, 0x42)))
+	require.False(t, cache.add(rtpPacket(3, 2000, 0x68, 0xce)))
+	require.True(t, cache.add(rtpPacket(4, 2000, 0x7c, 0x85, 0x88)))
+	require.True(t, cache.add(rtpPacket(5, 2000, 0x7c, 0x45, 0x88)))
+	require.True(t, cache.add(rtpPacket(6, 3000, 0x41, 0x9a)))
+	require.Len(t, cache.packets, 5, "parameter sets are kept with the keyframe")
+
+	require.True(t, cache.add(rtpPacket(7, 4000, 0x65, 0x88)))
+	require.Len(t, cache.packets, 1, "a new keyframe starts a new GOP")
+	require.Equal(t, uint16(7), binary.BigEndian.Uint16(cache.packets[0][2:]))
+}
+
+func TestForwardRTPPacketReplaysGOP(t *testing.T) {
+	conn := listenLocalUDP(t)
+	client := listenLocalUDP(t)
+
+	sourceAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5004}
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	streams[1] = &Stream{sources: []*source{{address: *sourceAddr}}, clients: make(map[string]*Endpoint), gop: newGOPCache(pb.VideoCodec_H264), stats: newCounters()}
+	streamMap[addressKey(*sourceAddr)] = 1
+	defer func() {
+		delete(streams, 1)
+		delete(streamMap, addressKey(*sourceAddr))
+	}()
+
+	forwardRTPPacket(conn, rtpPacket(1, 1000, 0x65, 0x88), sourceAddr)
+	forwardRTPPacket(conn, rtpPacket(2, 2000, 0x41, 0x9a), sourceAddr)
+
+	clientAddr := client.LocalAddr().(*net.UDPAddr)
+	streams[1].clients[clientAddr.String()] = &Endpoint{enabled: true, address: *clientAddr, replay: true}
+	forwardRTPPacket(conn, rtpPacket(3, 3000, 0x41, 0x9a), sourceAddr)
+	forwardRTPPacket(conn, rtpPacket(4, 4000, 0x41, 0x9a), sourceAddr)
+
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	buffer := make([]byte, 1500)
+	for _, seq := range []uint16{1, 2, 3, 4} {
+		n, err := client.Read(buffer)
+		require.NoError(t, err)
+		require.Equal(t, seq, binary.BigEndian.Uint16(buffer[2:n]))
+	}
+}
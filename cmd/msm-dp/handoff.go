@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+const (
+	// handoffTimeout is how long a handoff may take, including the new
+	// msm-dp recreating the streams
+	handoffTimeout = 30 * time.Second
+	// pauseTimeout is how long the forwarders are waited for to stop reading
+	pauseTimeout = time.Second
+	// maxHandoffSize bounds the snapshot of the streams received in a handoff
+	maxHandoffSize = 64 << 20
+)
+
+// takeover holds the sockets and streams handed off by the running msm-dp.
+// The streams are recreated from the control requests and continue from
+// their forwarding state, so the SRTP indexes, rewritten sequence numbers and
+// latched client addresses carry on.
+type takeover struct {
+	conn     *net.UnixConn
+	lis      net.Listener
+	rtpConn  *net.UDPConn
+	rtcpConn *net.UDPConn
+	snapshot *pb.Registry
+}
+
+// takeOver receives the sockets and streams of the msm-dp listening on the
+// handoff socket, or returns nil if there is none.
+func takeOver(path string) (*takeover, error) {
+	if path == "" {
+		return nil, nil
+	}
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		log.WithError(err).Infof("No msm-dp to take over from at %s", path)
+		return nil, nil
+	}
+	t, err := receiveHandoff(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	log.Infof("Took over the sockets of the msm-dp at %s", path)
+	return t, nil
+}
+
+func receiveHandoff(conn *net.UnixConn) (*takeover, error) {
+	if err := conn.SetDeadline(time.Now().Add(handoffTimeout)); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 8)
+	files, err := receiveFiles(conn, header, 3)
+	if err != nil {
+		return nil, err
+	}
+	// the sockets are duplicated from the files
+	defer closeFiles(files)
+	size := binary.BigEndian.Uint64(header)
+	if size > maxHandoffSize {
+		return nil, fmt.Errorf("handoff of %d bytes is too large", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(conn, data); err != nil {
+		return nil, fmt.Errorf("truncated handoff: %w", err)
+	}
+	snapshot := &pb.Registry{}
+	if err := protojson.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+
+	lis, err := net.FileListener(files[0])
+	if err != nil {
+		return nil, err
+	}
+	rtpConn, err := fileUDPConn(files[1])
+	if err != nil {
+		_ = lis.Close()
+		return nil, err
+	}
+	rtcpConn, err := fileUDPConn(files[2])
+	if err != nil {
+		_ = lis.Close()
+		_ = rtpConn.Close()
+		return nil, err
+	}
+	return &takeover{conn: conn, lis: lis, rtpConn: rtpConn, rtcpConn: rtcpConn, snapshot: snapshot}, nil
+}
+
+func fileUDPConn(f *os.File) (*net.UDPConn, error) {
+	conn, err := net.FileConn(f)
+	if err != nil {
+		return nil, err
+	}
+	udpConn, ok := conn.(*net.UDPConn)
+	if !ok {
+		_ = conn.Close()
+		return nil, fmt.Errorf("handed off socket %s isn't a UDP socket", f.Name())
+	}
+	return udpConn, nil
+}
+
+// complete tells the previous msm-dp to stop, once the streams are forwarded.
+func (t *takeover) complete() error {
+	defer t.conn.Close()
+	_, err := t.conn.Write([]byte{1})
+	return err
+}
+
+// listenHandoff listens for a new msm-dp to hand off to.
+func listenHandoff(path string) (*net.UnixListener, error) {
+	// left by an msm-dp that stopped or handed off to this one
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	// the msm-dp that takes over replaces the socket
+	ln.SetUnlinkOnClose(false)
+	return ln, nil
+}
+
+// serveHandoff hands the sockets and streams off to the first new msm-dp that
+// connects to the listener, and reports whether it took over. The streams
+// stop being forwarded until the new msm-dp confirms it forwards them, and
+// are forwarded again if it doesn't.
+func (s *server) serveHandoff(ln *net.UnixListener, lis net.Listener) bool {
+	for {
+		conn, err := ln.AcceptUnix()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.WithError(err).Warn("Stopped accepting handoffs")
+			}
+			return false
+		}
+		err = s.handOff(conn, lis)
+		_ = conn.Close()
+		if err == nil {
+			_ = ln.Close()
+			return true
+		}
+		log.WithError(err).Warn("Handoff failed, carrying on")
+	}
+}
+
+func (s *server) handOff(conn *net.UnixConn, lis net.Listener) error {
+	if err := checkPeer(conn); err != nil {
+		return err
+	}
+	if !s.draining.CompareAndSwap(false, true) {
+		return errors.New("already shutting down")
+	}
+	// the new msm-dp saves the streams from now on
+	file := registry.stopSaving()
+	handedOff := false
+	defer func() {
+		if !handedOff {
+			streamsLock.Lock()
+			registry.file = file
+			registry.wake()
+			streamsLock.Unlock()
+			s.draining.Store(false)
+		}
+	}()
+	log.Info("Handing off to a new msm-dp, control changes are rejected")
+
+	// packets the forwarders read after the snapshot would be lost to the new
+	// msm-dp, they are left in the sockets until one of them reads them
+	pause := pauseReads(s.rtpConn, s.rtcpConn)
+	defer func() { pause.end(handedOff) }()
+	// the streams aren't forwarded from the snapshot on, packets protected
+	// here would reuse the SRTP indexes the new msm-dp continues from
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	snapshot := registry.snapshot()
+	snapshot.States = streamStates()
+	data, err := protojson.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	files, err := socketFiles(lis, s.rtpConn, s.rtcpConn)
+	if err != nil {
+		return err
+	}
+	defer closeFiles(files)
+
+	if err := conn.SetDeadline(time.Now().Add(handoffTimeout)); err != nil {
+		return err
+	}
+	if err := sendFiles(conn, binary.BigEndian.AppendUint64(nil, uint64(len(data))), files...); err != nil {
+		return err
+	}
+	if _, err := conn.Write(data); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(conn, make([]byte, 1)); err != nil {
+		return fmt.Errorf("new msm-dp didn't take over: %w", err)
+	}
+	handedOff = true
+	for streamID := range streams {
+		deleteStream(streamID)
+	}
+	log.Info("New msm-dp took over")
+	return nil
+}
+
+// socketFiles returns duplicates of the sockets' descriptors.
+func socketFiles(sockets ...any) ([]*os.File, error) {
+	var files []*os.File
+	for _, socket := range sockets {
+		filer, ok := socket.(interface{ File() (*os.File, error) })
+		if !ok {
+			closeFiles(files)
+			return nil, fmt.Errorf("%T can't be handed off", socket)
+		}
+		f, err := filer.File()
+		if err != nil {
+			closeFiles(files)
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
+
+// readPause holds the forwarders of the shared sockets off reading while the
+// streams are handed off, so that the packets are read by one msm-dp only.
+type readPause struct {
+	conns  []*net.UDPConn
+	parked chan struct{}
+	// done is closed when the handoff ends, with handedOff set if the
+	// forwarders must stop
+	done      chan struct{}
+	handedOff bool
+}
+
+// pausedReads is the pause of the handoff in progress, if any.
+var pausedReads atomic.Pointer[readPause]
+
+// pauseReads interrupts the reads of the forwarders of the sockets, and waits
+// for them to park.
+func pauseReads(conns ...*net.UDPConn) *readPause {
+	p := &readPause{conns: conns, parked: make(chan struct{}, len(conns)), done: make(chan struct{})}
+	pausedReads.Store(p)
+	for _, conn := range conns {
+		if err := conn.SetReadDeadline(time.Now()); err != nil {
+			log.WithError(err).Warn("Could not pause the reads of a socket")
+		}
+	}
+	timeout := time.NewTimer(pauseTimeout)
+	defer timeout.Stop()
+	for range conns {
+		select {
+		case <-p.parked:
+		case <-timeout.C:
+			log.Warn("Forwarders didn't stop reading, packets may be lost")
+			return p
+		}
+	}
+	return p
+}
+
+// end stops the parked forwarders if the new msm-dp took over, and lets them
+// read again otherwise.
+func (p *readPause) end(handedOff bool) {
+	p.handedOff = handedOff
+	if !handedOff {
+		for _, conn := range p.conns {
+			if err := conn.SetReadDeadline(time.Time{}); err != nil {
+				log.WithError(err).Warn("Could not resume the reads of a socket")
+			}
+		}
+	}
+	close(p.done)
+	if !handedOff {
+		pausedReads.CompareAndSwap(p, nil)
+	}
+}
+
+// waitReads parks a forwarder whose read was interrupted by a handoff until
+// the handoff ends, and reports whether it may read again.
+func waitReads() bool {
+	p := pausedReads.Load()
+	if p == nil {
+		return true
+	}
+	select {
+	case p.parked <- struct{}{}:
+	default:
+	}
+	<-p.done
+	return !p.handedOff
+}
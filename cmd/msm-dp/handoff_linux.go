@@ -0,0 +1,91 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+)
+
+// checkPeer returns an error unless the process connected to the socket runs
+// as the same user, since it is handed the sockets and SRTP keys.
+func checkPeer(conn *net.UnixConn) error {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var cred *syscall.Ucred
+	var credErr error
+	err = rawConn.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return err
+	}
+	if credErr != nil {
+		return credErr
+	}
+	if int(cred.Uid) != os.Getuid() {
+		return fmt.Errorf("peer process %d runs as user %d", cred.Pid, cred.Uid)
+	}
+	return nil
+}
+
+// sendFiles writes the data with the descriptors of the files attached.
+func sendFiles(conn *net.UnixConn, data []byte, files ...*os.File) error {
+	fds := make([]int, len(files))
+	for i, f := range files {
+		// Fd would put the socket in blocking mode
+		rawConn, err := f.SyscallConn()
+		if err != nil {
+			return err
+		}
+		if err := rawConn.Control(func(fd uintptr) { fds[i] = int(fd) }); err != nil {
+			return err
+		}
+	}
+	n, _, err := conn.WriteMsgUnix(data, syscall.UnixRights(fds...), nil)
+	if err != nil {
+		return err
+	}
+	if n != len(data) {
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// receiveFiles fills the data and returns the count files whose descriptors
+// are attached to it.
+func receiveFiles(conn *net.UnixConn, data []byte, count int) ([]*os.File, error) {
+	oob := make([]byte, syscall.CmsgSpace(4*count))
+	n, oobn, flags, _, err := conn.ReadMsgUnix(data, oob)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil, err
+	}
+	var files []*os.File
+	for _, message := range messages {
+		fds, err := syscall.ParseUnixRights(&message)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			files = append(files, os.NewFile(uintptr(fd), fmt.Sprintf("handoff-%d", len(files))))
+		}
+	}
+	if flags&syscall.MSG_CTRUNC != 0 || len(files) != count {
+		closeFiles(files)
+		return nil, fmt.Errorf("received %d sockets, expected %d", len(files), count)
+	}
+	if _, err := io.ReadFull(conn, data[n:]); err != nil {
+		closeFiles(files)
+		return nil, fmt.Errorf("truncated handoff: %w", err)
+	}
+	return files, nil
+}
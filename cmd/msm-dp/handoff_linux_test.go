@@ -0,0 +1,139 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestHandoff(t *testing.T) {
+	useRegistry(t, "")
+	rtpConn := listenLocalUDP(t)
+	rtcpConn := listenLocalUDP(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	running := &server{rtpConn: rtpConn, rtcpConn: rtcpConn, stopping: make(chan struct{})}
+	rtpForwarded, rtcpForwarded := make(chan struct{}), make(chan struct{})
+	go func() {
+		forwardRTPPackets(rtpConn)
+		close(rtpForwarded)
+	}()
+	go func() {
+		forwardRTCPPackets(rtcpConn, rtpConn)
+		close(rtcpForwarded)
+	}()
+
+	source := listenLocalUDP(t)
+	client := listenLocalUDP(t)
+	sourceAddr, clientAddr := source.LocalAddr().(*net.UDPAddr), client.LocalAddr().(*net.UDPAddr)
+	for _, in := range []*pb.StreamData{
+		{Id: 21, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: uint32(sourceAddr.Port)}},
+		{Id: 21, Operation: pb.StreamOperation_ADD_EP, Enable: true, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: uint32(clientAddr.Port)}},
+	} {
+		result, err := running.StreamAddDel(context.Background(), in)
+		require.NoError(t, err)
+		require.True(t, result.Success)
+	}
+	defer func() {
+		streamsLock.Lock()
+		deleteStream(21)
+		streamsLock.Unlock()
+	}()
+	generation := registry.generation
+
+	send := func(seq uint16) {
+		_, err := source.WriteToUDP(rtpPacket(seq, 100, 0x65), rtpConn.LocalAddr().(*net.UDPAddr))
+		require.NoError(t, err)
+	}
+	buffer := make([]byte, 1500)
+	forwarded := func(seq uint16) {
+		require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+		n, err := client.Read(buffer)
+		require.NoError(t, err)
+		require.Equal(t, seq, rtpSequence(buffer[:n]))
+	}
+	send(1)
+	forwarded(1)
+
+	path := filepath.Join(t.TempDir(), "handoff.sock")
+	ln, err := listenHandoff(path)
+	require.NoError(t, err)
+	handedOff := make(chan bool)
+	go func() { handedOff <- running.serveHandoff(ln, lis) }()
+
+	// the new msm-dp exits before taking over
+	failed, err := takeOver(path)
+	require.NoError(t, err)
+	require.NotNil(t, failed)
+	require.True(t, running.draining.Load(), "control changes are rejected during the handoff")
+	require.NoError(t, failed.conn.Close())
+	require.NoError(t, failed.lis.Close())
+	require.NoError(t, failed.rtpConn.Close())
+	require.NoError(t, failed.rtcpConn.Close())
+	require.Eventually(t, func() bool { return !running.draining.Load() }, time.Second, time.Millisecond, "control changes are accepted again")
+	send(2)
+	forwarded(2)
+
+	takeover, err := takeOver(path)
+	require.NoError(t, err)
+	require.NotNil(t, takeover)
+	defer takeover.lis.Close()
+	defer takeover.rtpConn.Close()
+	defer takeover.rtcpConn.Close()
+	require.Equal(t, lis.Addr().String(), takeover.lis.Addr().String())
+	require.Equal(t, rtpConn.LocalAddr().String(), takeover.rtpConn.LocalAddr().String())
+	require.Equal(t, rtcpConn.LocalAddr().String(), takeover.rtcpConn.LocalAddr().String())
+	require.Equal(t, generation, takeover.snapshot.Generation)
+	require.Len(t, takeover.snapshot.Requests, 2)
+	require.Equal(t, uint32(21), takeover.snapshot.Requests[0].Id)
+	require.Len(t, takeover.snapshot.States, 1)
+
+	// packets sent during and after the takeover are all left to the new msm-dp
+	for seq := uint16(3); seq <= 10; seq++ {
+		if seq == 6 {
+			require.NoError(t, takeover.complete())
+			require.True(t, <-handedOff)
+		}
+		send(seq)
+	}
+	require.NoError(t, takeover.rtpConn.SetReadDeadline(time.Now().Add(time.Second)))
+	for seq := uint16(3); seq <= 10; seq++ {
+		n, _, err := takeover.rtpConn.ReadFromUDP(buffer)
+		require.NoError(t, err)
+		require.Equal(t, rtpPacket(seq, 100, 0x65), buffer[:n])
+	}
+	require.True(t, running.draining.Load())
+	<-rtpForwarded
+	<-rtcpForwarded
+
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	_, err = client.Read(buffer)
+	require.Error(t, err, "the previous msm-dp forwards nothing once handed off")
+}
+
+func TestTakeOverWithoutRunningDataPlane(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "handoff.sock")
+	takeover, err := takeOver(path)
+	require.NoError(t, err)
+	require.Nil(t, takeover)
+
+	// left by an msm-dp that stopped
+	ln, err := listenHandoff(path)
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+	takeover, err = takeOver(path)
+	require.NoError(t, err)
+	require.Nil(t, takeover)
+	ln, err = listenHandoff(path)
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+}
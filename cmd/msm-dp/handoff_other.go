@@ -0,0 +1,26 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"net"
+	"os"
+)
+
+var errHandoffUnsupported = errors.New("socket handoff is only supported on Linux")
+
+// checkPeer is only supported on Linux.
+func checkPeer(*net.UnixConn) error {
+	return errHandoffUnsupported
+}
+
+// sendFiles is only supported on Linux.
+func sendFiles(*net.UnixConn, []byte, ...*os.File) error {
+	return errHandoffUnsupported
+}
+
+// receiveFiles is only supported on Linux.
+func receiveFiles(*net.UnixConn, []byte, int) ([]*os.File, error) {
+	return nil, errHandoffUnsupported
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
@@ -9,39 +12,199 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// TODO - implement some checks to make sure all necessary resources/connections/interfaces are ready
-// Split liveness and Readiness checks
-const isEverythingReady = true
+const (
+	// readinessService is also the status of the server, the empty service name
+	readinessService = "readiness"
+	livenessService  = "liveness"
+	// streamsHealthService is the service name whose status is NOT_SERVING while any stream is stalled.
+	streamsHealthService = "streams"
 
-type HealthChecker struct{}
+	// livenessTimeout is how long the stream monitor can go without a tick
+	// before the process is considered stuck
+	livenessTimeout = 5 * time.Second
+)
+
+// Components of the data plane that must be up for it to be ready.
+const (
+	componentRTPSocket     = "rtp-socket"
+	componentRTCPSocket    = "rtcp-socket"
+	componentRTPForwarder  = "rtp-forwarder"
+	componentRTCPForwarder = "rtcp-forwarder"
+	componentControlServer = "control-server"
+)
+
+var (
+	readinessComponents = []string{componentRTPSocket, componentRTCPSocket, componentRTPForwarder, componentRTCPForwarder, componentControlServer}
+	livenessComponents  = []string{componentRTPForwarder, componentRTCPForwarder}
+)
+
+// monitorHeartbeat is the time of the stream monitor's last tick, in Unix nanoseconds.
+var monitorHeartbeat atomic.Int64
+
+var (
+	statusMu sync.Mutex
+	// statusChanges is closed and replaced when a status may have changed,
+	// to wake up the watchers
+	statusChanges = make(chan struct{})
+)
+
+// notifyStatus wakes up the watchers of the statuses.
+func notifyStatus() {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	close(statusChanges)
+	statusChanges = make(chan struct{})
+}
+
+// statusChanged returns a channel closed at the next change of a status.
+func statusChanged() <-chan struct{} {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	return statusChanges
+}
+
+type HealthChecker struct {
+	mu         sync.Mutex
+	components map[string]bool
+	// stopping is closed when the server shuts down, which ends the watches
+	stopping chan struct{}
+	stopOnce sync.Once
+}
 
 func NewHealthChecker() *HealthChecker {
-	return &HealthChecker{}
-}
-
-func (h *HealthChecker) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
-	log.Infof("Serving the Check request for health check")
-
-	if isEverythingReady == true {
-		log.Debugf("✅ Server's status is %s", grpc_health_v1.HealthCheckResponse_SERVING)
-		return &grpc_health_v1.HealthCheckResponse{
-			Status: grpc_health_v1.HealthCheckResponse_SERVING,
-		}, nil
-	} else if isEverythingReady == false {
-		log.Debugf("🚫 Server's status is %s", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
-		return &grpc_health_v1.HealthCheckResponse{
-			Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
-		}, nil
+	return &HealthChecker{components: make(map[string]bool), stopping: make(chan struct{})}
+}
+
+// setReady records whether a component is up.
+func (h *HealthChecker) setReady(component string, ready bool) {
+	h.mu.Lock()
+	changed := h.components[component] != ready
+	h.components[component] = ready
+	h.mu.Unlock()
+	if changed {
+		log.Infof("Component %s ready: %v", component, ready)
+		notifyStatus()
+	}
+}
+
+// stop ends the watches, once the server has started shutting down.
+func (h *HealthChecker) stop() {
+	h.stopOnce.Do(func() {
+		close(h.stopping)
+	})
+}
+
+// run runs a goroutine of a component, which is up until the goroutine returns.
+func (h *HealthChecker) run(component string, f func()) {
+	h.setReady(component, true)
+	defer h.setReady(component, false)
+	f()
+}
+
+func (h *HealthChecker) ready(components []string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, component := range components {
+		if !h.components[component] {
+			return false
+		}
+	}
+	return true
+}
+
+// status returns the status of a service, SERVICE_UNKNOWN for unknown services.
+func (h *HealthChecker) status(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	serving := false
+	switch service {
+	case "", readinessService:
+		serving = h.ready(readinessComponents)
+	case livenessService:
+		heartbeat := time.Unix(0, monitorHeartbeat.Load())
+		serving = h.ready(livenessComponents) && time.Since(heartbeat) < livenessTimeout
+	case streamsHealthService:
+		if stalled := stalledStreams(); stalled > 0 {
+			log.Debugf("🚫 %d streams are stalled", stalled)
+		} else {
+			serving = true
+		}
+	default:
+		return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+	if serving {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
+
+func (h *HealthChecker) Check(_ context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	log.Tracef("Serving the Check request for health check of %q", req.Service)
+
+	servingStatus := h.status(req.Service)
+	if servingStatus == grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.Service)
+	}
+	if servingStatus == grpc_health_v1.HealthCheckResponse_SERVING {
+		log.Debugf("✅ Status of %q is %s", req.Service, servingStatus)
 	} else {
-		log.Debugf("🚫 Server's status is %s", grpc_health_v1.HealthCheckResponse_UNKNOWN)
-		return &grpc_health_v1.HealthCheckResponse{
-			Status: grpc_health_v1.HealthCheckResponse_UNKNOWN,
-		}, nil
+		log.Debugf("🚫 Status of %q is %s", req.Service, servingStatus)
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+// Watch sends the status of the service, then every change of its status
+// until the client cancels the call. The service is reported NOT_SERVING and
+// the call ends when the server shuts down.
+func (h *HealthChecker) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	sent := false
+	var last grpc_health_v1.HealthCheckResponse_ServingStatus
+	for {
+		changed := statusChanged()
+		servingStatus := h.status(req.Service)
+		stopping := h.stopped()
+		if stopping {
+			servingStatus = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+		if !sent || servingStatus != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+			sent = true
+			last = servingStatus
+		}
+		if stopping {
+			return status.Error(codes.Unavailable, "msm-dp is shutting down")
+		}
+		if err := h.wait(stream.Context(), req.Service, last, changed); err != nil {
+			return err
+		}
 	}
 }
 
-// Watch is used by clients to receive updates when the service status changes.
-// Watch only dummy implemented just to satisfy the interface.
-func (h *HealthChecker) Watch(*grpc_health_v1.HealthCheckRequest, grpc_health_v1.Health_WatchServer) error {
-	return status.Error(codes.Unimplemented, "Watching is not supported")
+func (h *HealthChecker) stopped() bool {
+	select {
+	case <-h.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait returns when the status of the service may have changed from the last
+// one sent, or the server shuts down, or with the error of a cancelled call.
+func (h *HealthChecker) wait(ctx context.Context, service string, last grpc_health_v1.HealthCheckResponse_ServingStatus, changed <-chan struct{}) error {
+	// the ticks of the stream monitor aren't notified, its heartbeat expires
+	var expired <-chan time.Time
+	if service == livenessService && last == grpc_health_v1.HealthCheckResponse_SERVING {
+		timer := time.NewTimer(time.Until(time.Unix(0, monitorHeartbeat.Load()).Add(livenessTimeout)))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-h.stopping:
+	case <-changed:
+	case <-expired:
+	}
+	return nil
 }
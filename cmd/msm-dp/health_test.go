@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestHealthStatus(t *testing.T) {
+	h := NewHealthChecker()
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, h.status(""), "not ready until the components are up")
+	for _, component := range readinessComponents {
+		h.setReady(component, true)
+	}
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, h.status(""))
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, h.status(readinessService))
+
+	monitorHeartbeat.Store(time.Now().UnixNano())
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, h.status(livenessService))
+	monitorHeartbeat.Store(time.Now().Add(-livenessTimeout).UnixNano())
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, h.status(livenessService), "the stream monitor is stuck")
+
+	done := make(chan struct{})
+	go h.run(componentRTPForwarder, func() { <-done })
+	close(done)
+	require.Eventually(t, func() bool {
+		return h.status(readinessService) == grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 10*time.Millisecond, "a forwarder that returned is down")
+
+	_, err := h.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestHealthWatch(t *testing.T) {
+	h := NewHealthChecker()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, h)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watch, err := grpc_health_v1.NewHealthClient(conn).Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: readinessService})
+	require.NoError(t, err)
+
+	response, err := watch.Recv()
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+	for _, component := range readinessComponents {
+		h.setReady(component, true)
+	}
+	response, err = watch.Recv()
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	unknown, err := grpc_health_v1.NewHealthClient(conn).Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	require.NoError(t, err)
+	response, err = unknown.Recv()
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN, response.Status)
+
+	start := time.Now()
+	h.setReady(componentRTPForwarder, false)
+	response, err = watch.Recv()
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+	require.Less(t, time.Since(start), 500*time.Millisecond, "changes are sent when they happen")
+	h.setReady(componentRTPForwarder, true)
+	response, err = watch.Recv()
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	h.stop()
+	response, err = watch.Recv()
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status, "shutting down")
+	_, err = watch.Recv()
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"container/list"
+	"net"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// logInterval is how often a rate limited message is logged
+	logInterval = 5 * time.Second
+	// maxTrackedSenders bounds the senders rate limited, the least recently
+	// seen one is forgotten to track a new one
+	maxTrackedSenders = 16384
+)
+
+// logLimiter logs each kind of message at most once per interval, with the
+// number of messages suppressed since.
+type logLimiter struct {
+	mu         sync.Mutex
+	interval   time.Duration
+	last       map[string]time.Time
+	suppressed map[string]int
+}
+
+func newLogLimiter(interval time.Duration) *logLimiter {
+	return &logLimiter{interval: interval, last: make(map[string]time.Time), suppressed: make(map[string]int)}
+}
+
+// entry returns the entry to log a message of the kind with, or nil if the
+// message must be suppressed.
+func (l *logLimiter) entry(kind string) *log.Entry {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if last, ok := l.last[kind]; ok && now.Sub(last) < l.interval {
+		l.suppressed[kind]++
+		return nil
+	}
+	l.last[kind] = now
+	entry := log.NewEntry(log.StandardLogger())
+	if suppressed := l.suppressed[kind]; suppressed > 0 {
+		entry = entry.WithField("suppressed", suppressed)
+		delete(l.suppressed, kind)
+	}
+	return entry
+}
+
+// ingressLog limits the messages logged for each received packet.
+var ingressLog = newLogLimiter(logInterval)
+
+// ingressLimiter limits the packet rates of each sender IP, separately for
+// RTP and RTCP so that a sender's RTP can't starve its RTCP.
+type ingressLimiter struct {
+	mu  sync.Mutex
+	pps uint32
+	// senders are the elements of lru, which orders the senders from the most
+	// to the least recently seen
+	senders map[senderKey]*list.Element
+	lru     *list.List
+}
+
+type senderKey struct {
+	addr netip.Addr
+	rtcp bool
+}
+
+type trackedSender struct {
+	key    senderKey
+	bucket *tokenBucket
+}
+
+func newIngressLimiter(pps uint32) *ingressLimiter {
+	return &ingressLimiter{pps: pps, senders: make(map[senderKey]*list.Element), lru: list.New()}
+}
+
+// newPacketBucket returns a bucket of packets per second, with a burst of one second.
+func newPacketBucket(pps uint32, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: float64(pps), burst: float64(pps), tokens: float64(pps), last: now}
+}
+
+// allow reports whether an RTP or RTCP packet from the sender is within its rate.
+func (l *ingressLimiter) allow(sender *net.UDPAddr, rtcp bool, now time.Time) bool {
+	addr, _ := netip.AddrFromSlice(sender.IP)
+	key := senderKey{addr: addr.Unmap(), rtcp: rtcp}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	element, ok := l.senders[key]
+	switch {
+	case ok:
+		l.lru.MoveToFront(element)
+	case l.lru.Len() >= maxTrackedSenders:
+		// the least recently seen sender makes room
+		element = l.lru.Back()
+		tracked := element.Value.(*trackedSender)
+		delete(l.senders, tracked.key)
+		tracked.key, tracked.bucket = key, newPacketBucket(l.pps, now)
+		l.senders[key] = element
+		l.lru.MoveToFront(element)
+	default:
+		element = l.lru.PushFront(&trackedSender{key: key, bucket: newPacketBucket(l.pps, now)})
+		l.senders[key] = element
+	}
+	return element.Value.(*trackedSender).bucket.allow(1, false, now)
+}
+
+// ingressSettings are the checks of received packets, replaced as a whole
+// when the config is reloaded.
+type ingressSettings struct {
+	// limiter is set when the packet rate of senders is limited
+	limiter *ingressLimiter
+	strict  bool
+}
+
+var ingress atomic.Pointer[ingressSettings]
+
+// applyIngressFlags sets the checks of received packets from the flags. The
+// rates of the senders are kept if their limit is unchanged.
+func applyIngressFlags() {
+	settings := &ingressSettings{strict: *strictIngress}
+	if pps := uint32(*maxSourcePps); pps > 0 {
+		if previous := ingress.Load(); previous != nil && previous.limiter != nil && previous.limiter.pps == pps {
+			settings.limiter = previous.limiter
+		} else {
+			settings.limiter = newIngressLimiter(pps)
+		}
+	}
+	ingress.Store(settings)
+}
+
+// acceptIngress reports whether a received packet may be looked up, when it
+// is from a stream's source or within its sender's rate and, in strict mode,
+// well-formed or identifying a client waiting to latch.
+// Must be called with streamsLock held.
+func acceptIngress(packet []byte, sender *net.UDPAddr, rtcp bool) bool {
+	settings := ingress.Load()
+	if settings == nil {
+		return true
+	}
+	if settings.limiter != nil && !isSource(sender, rtcp) && !settings.limiter.allow(sender, rtcp, time.Now()) {
+		if entry := ingressLog.entry("rate-limited"); entry != nil {
+			entry.Warnf("Dropping packets from %v above %d packets per second", sender, settings.limiter.pps)
+		}
+		return false
+	}
+	if !settings.strict {
+		return true
+	}
+	valid := validRTP(packet)
+	if rtcp {
+		valid = validRTCP(packet)
+	}
+	if !valid {
+		// the first packet of a client latching with a token is the token
+		if _, _, endpoint := pendingLatch(packet, sender, rtcp); endpoint != nil {
+			return true
+		}
+		if entry := ingressLog.entry("malformed"); entry != nil {
+			entry.Warnf("Dropping malformed packet from %v", sender)
+		}
+	}
+	return valid
+}
+
+// isSource reports whether a packet is from the RTP or RTCP port of a
+// stream's source.
+// Must be called with streamsLock held.
+func isSource(sender *net.UDPAddr, rtcp bool) bool {
+	address := *sender
+	if rtcp {
+		address.Port--
+	}
+	_, ok := streamMap[addressKey(address)]
+	return ok
+}
+
+// validRTP reports whether a packet is an RTP packet, with a header that fits
+// and a payload type that can't be mistaken for RTCP. The payload and padding
+// aren't checked, they are encrypted in SRTP packets.
+func validRTP(packet []byte) bool {
+	if _, ok := rtpHeaderLength(packet); !ok {
+		return false
+	}
+	payloadType := packet[1] & 0x7f
+	return payloadType < 72 || payloadType > 76
+}
+
+// validRTCP reports whether a packet starts with an RTCP header that fits.
+// Only the first header is checked, the rest is encrypted in SRTCP packets.
+func validRTCP(packet []byte) bool {
+	if len(packet) < 8 || packet[0]>>6 != 2 {
+		return false
+	}
+	if packet[1] < 192 || packet[1] > 223 {
+		return false
+	}
+	length := 4 * (int(packet[2])<<8 | int(packet[3]) + 1)
+	return length <= len(packet)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestLogLimiter(t *testing.T) {
+	l := newLogLimiter(time.Hour)
+	require.NotNil(t, l.entry("unknown"))
+	require.Nil(t, l.entry("unknown"))
+	require.Nil(t, l.entry("unknown"))
+	require.NotNil(t, l.entry("malformed"), "kinds are limited separately")
+
+	l.last["unknown"] = time.Now().Add(-2 * time.Hour)
+	entry := l.entry("unknown")
+	require.NotNil(t, entry)
+	require.Equal(t, 2, entry.Data["suppressed"])
+}
+
+func TestIngressLimiter(t *testing.T) {
+	l := newIngressLimiter(10)
+	now := time.Now()
+	sender := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+	for i := 0; i < 10; i++ {
+		require.True(t, l.allow(sender, false, now))
+	}
+	require.False(t, l.allow(sender, false, now))
+	require.False(t, l.allow(&net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 6000}, false, now), "senders are limited per IP")
+	require.True(t, l.allow(sender, true, now), "RTCP is limited apart from RTP")
+	require.True(t, l.allow(&net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 5000}, false, now))
+	require.True(t, l.allow(sender, false, now.Add(100*time.Millisecond)))
+
+	// past the tracked senders, the least recently seen one is forgotten
+	l = newIngressLimiter(1)
+	first := &net.UDPAddr{IP: net.IPv4(10, 1, 0, 0)}
+	for i := 0; i < maxTrackedSenders; i++ {
+		require.True(t, l.allow(&net.UDPAddr{IP: net.IPv4(10, 1, byte(i>>8), byte(i))}, false, now))
+	}
+	require.False(t, l.allow(first, false, now), "the first sender is still tracked, and now the most recently seen")
+	require.True(t, l.allow(&net.UDPAddr{IP: net.IPv4(10, 2, 0, 1)}, false, now))
+	require.True(t, l.allow(&net.UDPAddr{IP: net.IPv4(10, 2, 0, 2)}, false, now), "new senders don't share a limit")
+	require.False(t, l.allow(first, false, now), "recently seen senders are kept")
+	require.Len(t, l.senders, maxTrackedSenders)
+	require.Equal(t, maxTrackedSenders, l.lru.Len())
+}
+
+func TestIngressLimiterExemptsSources(t *testing.T) {
+	*maxSourcePps = 1
+	applyIngressFlags()
+	defer func() {
+		*maxSourcePps = 0
+		applyIngressFlags()
+	}()
+	source := net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7420}
+	other := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7430}
+
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	streamMap[addressKey(source)] = 28
+	defer delete(streamMap, addressKey(source))
+
+	for i := 0; i < 3; i++ {
+		require.True(t, acceptIngress(rtpPacket(uint16(i), 100), &source, false))
+		require.True(t, acceptIngress(rtcpBYEPacket(1, ""), &net.UDPAddr{IP: source.IP, Port: source.Port + 1}, true))
+	}
+	require.True(t, acceptIngress(rtpPacket(1, 100), other, false))
+	require.False(t, acceptIngress(rtpPacket(2, 100), other, false), "other senders from the source's IP are limited")
+}
+
+func TestStrictIngress(t *testing.T) {
+	*strictIngress = true
+	applyIngressFlags()
+	defer func() {
+		*strictIngress = false
+		applyIngressFlags()
+	}()
+	sender := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+
+	require.True(t, acceptIngress(rtpPacket(1, 100, 0x65), sender, false))
+	require.False(t, acceptIngress([]byte("junk"), sender, false))
+	require.False(t, acceptIngress(rtpPacket(1, 100)[:8], sender, false))
+	rtcpLike := rtpPacket(1, 100)
+	rtcpLike[1] = 72
+	require.False(t, acceptIngress(rtcpLike, sender, false), "payload types conflicting with RTCP")
+	withCSRC := rtpPacket(1, 100)
+	withCSRC[0] |= 2
+	require.False(t, acceptIngress(withCSRC, sender, false), "CSRC list past the end")
+
+	bye := rtcpBYEPacket(0x12345678, "bye")
+	require.True(t, acceptIngress(bye, sender, true))
+	require.True(t, acceptIngress(append(bye, make([]byte, srtcpIndexLen+srtcpTagLen)...), sender, true), "SRTCP trailers are allowed")
+	require.False(t, acceptIngress([]byte{0x80, rtcpRR, 0x00, 0x05, 0x00, 0x00, 0x00, 0x01}, sender, true), "first packet past the end")
+	require.False(t, acceptIngress(rtpPacket(1, 100, 0x65), sender, true))
+	require.False(t, acceptIngress([]byte{0x80, 200, 0, 0}, sender, true))
+}
+
+func TestStrictIngressLatching(t *testing.T) {
+	*strictIngress = true
+	applyIngressFlags()
+	defer func() {
+		*strictIngress = false
+		applyIngressFlags()
+	}()
+	s := &server{}
+	for _, in := range []*pb.StreamData{
+		{Id: 31, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7440}},
+		{
+			Id: 31, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: "0.0.0.0", Port: 0},
+			Enable: true, Latch: pb.LatchMode_LATCH_TOKEN, LatchToken: []byte("secret"),
+		},
+	} {
+		result, err := s.StreamAddDel(context.Background(), in)
+		require.NoError(t, err)
+		require.True(t, result.Success)
+	}
+	defer s.StreamAddDel(context.Background(), &pb.StreamData{Id: 31, Operation: pb.StreamOperation_DELETE})
+
+	client := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7442}
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	require.False(t, acceptIngress([]byte("wrong!"), client, false))
+	require.True(t, acceptIngress([]byte("secret"), client, false), "tokens aren't RTP")
+	forwardRTPPacket(nil, []byte("secret"), client)
+	for _, endpoint := range streams[31].clients {
+		require.True(t, endpoint.latch.latched)
+		require.Equal(t, 7442, endpoint.address.Port)
+	}
+	require.False(t, acceptIngress([]byte("secret"), client, false), "once latched, packets must be well-formed")
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultLatchTimeout = 30 * time.Second
+
+// latch describes how the address of a client behind NAT is learned from
+// the first RTP or RTCP packet it sends to msm-dp (symmetric RTP).
+type latch struct {
+	mode  pb.LatchMode
+	ssrc  uint32
+	token []byte
+	// enable is the requested state of the endpoint once it has latched
+	enable   bool
+	deadline time.Time
+	// latched is set once the client has sent a packet and is forwarded to,
+	// rtpLatched and rtcpLatched once its RTP and RTCP ports are learned
+	latched     bool
+	rtpLatched  bool
+	rtcpLatched bool
+}
+
+func newLatch(in *pb.StreamData, now time.Time) *latch {
+	timeout := time.Duration(in.LatchTimeoutMs) * time.Millisecond
+	if timeout == 0 {
+		timeout = defaultLatchTimeout
+	}
+	return &latch{
+		mode:     in.Latch,
+		ssrc:     in.LatchSsrc,
+		token:    in.LatchToken,
+		enable:   in.Enable,
+		deadline: now.Add(timeout),
+	}
+}
+
+// matches reports whether a packet received from address identifies the client.
+// To prevent hijacking, the packet must come from the configured IP address
+// if there is one, and the SSRC or token must match in those modes.
+func (l *latch) matches(configured net.UDPAddr, packet []byte, address *net.UDPAddr, rtcp bool) bool {
+	hasIP := configured.IP != nil && !configured.IP.IsUnspecified()
+	if hasIP && !sameIP(configured, *address) {
+		return false
+	}
+	switch l.mode {
+	case pb.LatchMode_LATCH_SOURCE_IP:
+		return hasIP
+	case pb.LatchMode_LATCH_SSRC:
+		if rtcp {
+			return len(packet) >= 8 && packet[0]>>6 == 2 && binary.BigEndian.Uint32(packet[4:8]) == l.ssrc
+		}
+		return len(packet) >= rtpHeaderLen && packet[0]>>6 == 2 && rtpSSRC(packet) == l.ssrc
+	case pb.LatchMode_LATCH_TOKEN:
+		return len(l.token) > 0 && len(packet) >= len(l.token) &&
+			subtle.ConstantTimeCompare(packet[:len(l.token)], l.token) == 1
+	default:
+		return false
+	}
+}
+
+// latchClient learns the RTP or RTCP address of a client waiting to latch from
+// a packet received from an unknown address and reports whether it was learned.
+// Until a client has sent from both its ports, the other one is assumed to be
+// next to the one it sent from.
+// Must be called with streamsLock held.
+func latchClient(packet []byte, address *net.UDPAddr, rtcp bool) bool {
+	streamID, stream, endpoint := pendingLatch(packet, address, rtcp)
+	if endpoint == nil {
+		return false
+	}
+	l := endpoint.latch
+	learned := net.UDPAddr{IP: address.IP, Port: address.Port, Zone: address.Zone}
+	if err := checkDestination(learned); err != nil {
+		log.WithError(err).Warnf("Client latching to %v in stream %v rejected", learned, streamID)
+		return false
+	}
+	rtpAddress := endpoint.address
+	switch {
+	case !rtcp:
+		rtpAddress = learned
+	case !l.latched:
+		rtpAddress = net.UDPAddr{IP: learned.IP, Port: learned.Port - 1, Zone: learned.Zone}
+	}
+	if !l.latched || !sameAddress(rtpAddress, endpoint.address) {
+		if _, taken := streamMap[addressKey(rtpAddress)]; taken || clientExists(rtpAddress) {
+			log.Warnf("Client latching to %v in stream %v rejected, address already in use", rtpAddress, streamID)
+			return false
+		}
+	}
+	endpoint.address = rtpAddress
+	if rtcp {
+		l.rtcpLatched = true
+		endpoint.rtcp = &learned
+	} else {
+		l.rtpLatched = true
+	}
+	if l.latched {
+		log.Infof("Client %v in stream %v latched its %s port %v", endpoint.address, streamID, channelName(rtcp), learned)
+		return true
+	}
+	l.latched = true
+	endpoint.enabled = l.enable
+	endpoint.replay = stream.gop != nil
+	endpoint.revive(time.Now())
+	log.Infof("Client latched to %v in stream %v", rtpAddress, streamID)
+	events.publish(endpointEvent(streamID, pb.StreamEventType_CLIENT_LATCHED, rtpAddress, ""))
+	return true
+}
+
+// pendingLatch returns the client waiting to latch the port a packet is
+// received from, or nil if the packet doesn't identify one.
+// Must be called with streamsLock held.
+func pendingLatch(packet []byte, address *net.UDPAddr, rtcp bool) (uint32, *Stream, *Endpoint) {
+	for streamID, stream := range streams {
+		for _, endpoint := range stream.clients {
+			l := endpoint.latch
+			if l != nil && !(rtcp && l.rtcpLatched) && !(!rtcp && l.rtpLatched) && l.matches(endpoint.address, packet, address, rtcp) {
+				return streamID, stream, endpoint
+			}
+		}
+	}
+	return 0, nil, nil
+}
+
+func channelName(rtcp bool) string {
+	if rtcp {
+		return "RTCP"
+	}
+	return "RTP"
+}
+
+// clientKey returns the key of a request's client in its stream's clients.
+// Clients waiting to latch may share a placeholder address, so they are also
+// told apart by the SSRC or token they latch with.
+func clientKey(in *pb.StreamData) string {
+	client := parseAddress(in.Endpoint.GetIp(), in.Endpoint.GetPort())
+	switch in.Latch {
+	case pb.LatchMode_LATCH_SSRC:
+		return fmt.Sprintf("%v/ssrc:%08x", &client, in.LatchSsrc)
+	case pb.LatchMode_LATCH_TOKEN:
+		return fmt.Sprintf("%v/token:%x", &client, in.LatchToken)
+	default:
+		return client.String()
+	}
+}
+
+// clientExists reports whether any stream has a client with the address.
+func clientExists(address net.UDPAddr) bool {
+	for _, stream := range streams {
+		for _, endpoint := range stream.clients {
+			if (endpoint.latch == nil || endpoint.latch.latched) && sameAddress(endpoint.address, address) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkLatches removes the clients of the stream that haven't latched before their deadline.
+func checkLatches(streamID uint32, stream *Stream, now time.Time) {
+	for key, endpoint := range stream.clients {
+		if l := endpoint.latch; l != nil && !l.latched && now.After(l.deadline) {
+			endpoint.close()
+			delete(stream.clients, key)
+			registry.forgetClient(streamID, key)
+			log.Warnf("Client %v of stream %v removed, no packet to latch to", endpoint.address, streamID)
+			events.publish(endpointEvent(streamID, pb.StreamEventType_LATCH_TIMEOUT, endpoint.address, ""))
+		}
+	}
+}
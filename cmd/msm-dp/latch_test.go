@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestLatchClient(t *testing.T) {
+	s := &server{}
+	_, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 9, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7400},
+	})
+	require.NoError(t, err)
+	defer s.StreamAddDel(context.Background(), &pb.StreamData{Id: 9, Operation: pb.StreamOperation_DELETE})
+
+	_, err = s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 9, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: "10.0.0.2", Port: 5000},
+		Enable: true, Latch: pb.LatchMode_LATCH_TOKEN, LatchToken: []byte("secret"),
+	})
+	require.NoError(t, err)
+	_, err = s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 9, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 5002},
+		Enable: true, Latch: pb.LatchMode_LATCH_SOURCE_IP, LatchTimeoutMs: 100, PacingKbps: 1000,
+	})
+	require.NoError(t, err)
+
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	endpoint := streams[9].clients[clientKey(&pb.StreamData{
+		Endpoint: &pb.Endpoint{Ip: "10.0.0.2", Port: 5000}, Latch: pb.LatchMode_LATCH_TOKEN, LatchToken: []byte("secret"),
+	})]
+	require.False(t, endpoint.enabled, "not forwarded to before latching")
+
+	natted := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 40001}
+	require.False(t, latchClient([]byte("wrong!"), natted, true))
+	require.False(t, latchClient([]byte("secret"), &net.UDPAddr{IP: net.IPv4(10, 0, 0, 3), Port: 40001}, true),
+		"packets from another IP are ignored")
+	require.True(t, latchClient([]byte("secret"), natted, true))
+	require.True(t, endpoint.enabled)
+	require.Equal(t, 40000, endpoint.address.Port, "RTP port is assumed next to the RTCP port")
+	require.Equal(t, *natted, endpoint.rtcpAddress())
+
+	require.True(t, latchClient([]byte("secret"), &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 50000}, false),
+		"the RTP port is latched apart from the RTCP port")
+	require.Equal(t, 50000, endpoint.address.Port)
+	require.Equal(t, *natted, endpoint.rtcpAddress())
+	require.False(t, latchClient([]byte("secret"), &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 50002}, false),
+		"a latched client can't be moved")
+	require.False(t, latchClient([]byte("secret"), &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 50003}, true),
+		"a latched client can't be moved")
+
+	pacer := streams[9].clients[clientKey(&pb.StreamData{
+		Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 5002}, Latch: pb.LatchMode_LATCH_SOURCE_IP,
+	})].pacer
+	require.NotNil(t, pacer)
+	checkLatches(9, streams[9], time.Now().Add(time.Second))
+	require.Len(t, streams[9].clients, 1, "client that didn't latch in time is removed")
+	select {
+	case <-pacer.done:
+	default:
+		require.Fail(t, "the pacer of a removed client is stopped")
+	}
+}
+
+func TestLatchClientsSharingPlaceholder(t *testing.T) {
+	s := &server{}
+	_, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 25, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7410},
+	})
+	require.NoError(t, err)
+	defer s.StreamAddDel(context.Background(), &pb.StreamData{Id: 25, Operation: pb.StreamOperation_DELETE})
+
+	first := &pb.StreamData{
+		Id: 25, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: "0.0.0.0", Port: 0},
+		Enable: true, Latch: pb.LatchMode_LATCH_SSRC, LatchSsrc: 0x1111,
+	}
+	second := &pb.StreamData{
+		Id: 25, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: "0.0.0.0", Port: 0},
+		Enable: true, Latch: pb.LatchMode_LATCH_SSRC, LatchSsrc: 0x2222,
+	}
+	for _, in := range []*pb.StreamData{first, second} {
+		result, err := s.StreamAddDel(context.Background(), in)
+		require.NoError(t, err)
+		require.Empty(t, result.ErrorMessage)
+	}
+
+	streamsLock.Lock()
+	require.Len(t, streams[25].clients, 2)
+	require.True(t, latchClient(rtpPacketFrom(0x2222, 1, 0), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7420}, false))
+	require.Equal(t, 7420, streams[25].clients[clientKey(second)].address.Port)
+	require.False(t, streams[25].clients[clientKey(first)].latch.latched)
+	streamsLock.Unlock()
+
+	first.Operation = pb.StreamOperation_DEL_EP
+	result, err := s.StreamAddDel(context.Background(), first)
+	require.NoError(t, err)
+	require.Empty(t, result.ErrorMessage)
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	require.Len(t, streams[25].clients, 1)
+	require.Contains(t, streams[25].clients, clientKey(second))
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"flag"
-	"fmt"
 	"net"
+	"net/netip"
 	"os"
-	"strings"
+	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	"google.golang.org/grpc"
 
@@ -17,242 +26,851 @@ import (
 )
 
 var (
-	port    = flag.Int("port", 9000, "The server port")
-	rtpPort = flag.Int("rtpPort", 8050, "rtp port")
+	port          = flag.Int("port", 9000, "The server port")
+	rtpPort       = flag.Int("rtpPort", 8050, "rtp port")
+	listenAddress = flag.String("listenAddress", "", "IP address the control, RTP and RTCP sockets are bound to, all if not set")
+
+	videoDSCP = flag.Int("videoDSCP", unmarked, "default DSCP of video streams, such as 34 for AF41, unmarked if -1")
+	audioDSCP = flag.Int("audioDSCP", unmarked, "default DSCP of audio streams, such as 46 for EF, unmarked if -1")
+
+	configFile = flag.String("config", "", "YAML or JSON file of settings, reloaded on SIGHUP, the flags set on the command line take precedence")
+	logLevel   = flag.String("logLevel", os.Getenv("LOG_LEVEL"), "level of the messages logged, such as info or warn, debug if not set or invalid")
+	logFormat  = flag.String("logFormat", os.Getenv("LOG_TYPE"), "format of the messages logged, text or json, text if not set or invalid")
+
+	tlsCert           = flag.String("tlsCert", "", "certificate of the gRPC server, plaintext if not set")
+	tlsKey            = flag.String("tlsKey", "", "private key of the gRPC server certificate")
+	tlsCA             = flag.String("tlsCA", "", "CA that control plane client certificates must be signed by")
+	tlsReloadInterval = flag.Duration("tlsReloadInterval", 30*time.Second, "how often the certificate files are checked for changes")
+	authzPolicy       = flag.String("authzPolicy", "", "JSON policy of the operations allowed per caller, all allowed if not set")
+
+	allowedDestinationsFlag = flag.String("allowedDestinations", "", "comma separated CIDRs that clients may be in, any if not set")
+	allowedPortsFlag        = flag.String("allowedPorts", "", "comma separated ports or port ranges that clients may use, such as 1024-65535, any if not set")
+	allowLoopback           = flag.Bool("allowLoopback", false, "allow loopback clients")
+	allowMulticast          = flag.Bool("allowMulticast", false, "allow multicast clients")
+	allowBroadcast          = flag.Bool("allowBroadcast", false, "allow broadcast clients")
+
+	maxEgressKbps = flag.Uint("maxEgressKbps", 0, "bitrate above which RTP packets sent by the node are dropped, unlimited if not set")
+	maxSourcePps  = flag.Uint("maxSourcePps", 0, "packets per second above which packets from a sender IP are dropped, unlimited if not set")
+	strictIngress = flag.Bool("strictIngress", false, "drop packets that aren't well-formed RTP or RTCP before looking up their stream")
+
+	stateFile     = flag.String("stateFile", "", "file the streams are saved to on every change and restored from at startup, not saved if not set")
+	handoffSocket = flag.String("handoffSocket", "", "Unix socket over which the running msm-dp hands its sockets and streams off to a new msm-dp, for upgrades that don't drop media, disabled if not set")
+	drainPeriod   = flag.Duration("drainPeriod", 0, "how long streams keep being forwarded after SIGTERM or SIGINT")
 )
 
 type Endpoint struct {
 	enabled bool
 	address net.UDPAddr
+	// replay is set when the cached GOP must be sent before live packets
+	replay bool
+	// packets and payload octets sent, reported in generated sender reports
+	packets uint32
+	octets  uint32
+	// liveness of the client, from its RTCP packets and ICMP errors
+	added       time.Time
+	lastRTCP    time.Time
+	unreachable int
+	dead        bool
+	// latch is set when the client's address is learned from its first packet
+	latch *latch
+	// rtcp is the address of the client's RTCP port when it was latched
+	rtcp *net.UDPAddr
+	// multicast is set when the client is a multicast group
+	multicast *multicastSender
+	// dscp marks the packets sent to the client, unless unmarked
+	dscp int
+	// pacer and limit are set when the client is paced or its bandwidth capped
+	pacer *pacer
+	limit *tokenBucket
+	// srtp is set when the client exchanges SRTP, and srtpSent when the
+	// stream caches packets to answer NACKs, with the packets as protected
+	srtp     *srtpContext
+	srtpSent *nackCache
+}
+
+func (e *Endpoint) sent(packet []byte) {
+	e.packets++
+	if payload, ok := rtpPayload(packet); ok {
+		e.octets += uint32(len(payload))
+	}
 }
 
 type Stream struct {
-	server  net.UDPAddr
-	clients map[string]Endpoint
+	// sources are the primary source followed by any redundant or backup sources
+	sources []*source
+	merger  *seqMerger
+	// ingress is set when the source is a multicast group
+	ingress *multicastReceiver
+	// dscp is the default marking of the packets sent for the stream
+	dscp int
+	// pacingKbps and maxKbps are the default pacing and cap of the stream's clients
+	pacingKbps uint32
+	maxKbps    uint32
+	codec      pb.VideoCodec
+	// active is the index of the forwarded source when failing over between sources
+	active          int
+	failoverTimeout time.Duration
+	created         time.Time
+	// lastPacket is the arrival time of the last forwarded packet
+	lastPacket    time.Time
+	idleTimeout   time.Duration
+	removeTimeout time.Duration
+	stalled       bool
+	// ended is set when the source has sent an RTCP BYE
+	ended bool
+	// clientTimeout is the time without RTCP after which a client is considered gone
+	clientTimeout      time.Duration
+	disableDeadClients bool
+	clients            map[string]*Endpoint
+	gop                *gopCache
+	nack               *nackCache
+	rewriter           *rtpRewriter
+	translator         *rtcpTranslator
+	stats              *counters
 }
 
 var (
-	streams   = make(map[uint32]Stream)
-	streamMap = make(map[string]uint32)
+	// streamsLock protects streams, streamMap and the streams' contents
+	streamsLock sync.RWMutex
+	streams     = make(map[uint32]*Stream)
+	streamMap   = make(map[netip.AddrPort]uint32)
 )
 
 // server is used to implement msm_dp.server
 type server struct {
 	pb.UnimplementedMsmDataPlaneServer
+	// the shared sockets, used to forward streams received on other sockets
+	rtpConn  *net.UDPConn
+	rtcpConn *net.UDPConn
+	// draining is set and stopping closed when the server shuts down, draining
+	// is also set while handing off to a new msm-dp
+	draining atomic.Bool
+	stopping chan struct{}
 }
 
 func (s *server) StreamAddDel(_ context.Context, in *pb.StreamData) (*pb.StreamResult, error) {
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	// checked with the lock held, so that no change is missed by a handoff
+	if s.draining.Load() {
+		return nil, status.Error(codes.Unavailable, "msm-dp is shutting down")
+	}
+
+	result, changed := s.streamAddDel(in)
+	if changed {
+		registry.record(in)
+	}
+	result.Generation = registry.generation
+	return result, nil
+}
+
+// streamAddDel applies a control request and reports whether it changed the streams.
+// Must be called with streamsLock held.
+func (s *server) streamAddDel(in *pb.StreamData) (*pb.StreamResult, bool) {
+	if in.Endpoint == nil && in.Operation != pb.StreamOperation_DELETE {
+		log.Errorf("%v request of stream %v has no endpoint", in.Operation, in.Id)
+		return &pb.StreamResult{ErrorMessage: "endpoint is required"}, false
+	}
 	switch in.Operation.String() {
 	case "CREATE":
 		// check if the stream already exists in the streams map
 		_, exists := streams[in.Id]
 		if !exists {
-			streams[in.Id] = Stream{
-				server:  net.UDPAddr{IP: net.ParseIP(in.Endpoint.Ip), Port: int(in.Endpoint.Port), Zone: ""},
-				clients: make(map[string]Endpoint),
+			if len(in.RedundantSources) > 0 && len(in.BackupSources) > 0 {
+				log.Errorf("Stream with ID %d can't have both redundant and backup sources", in.Id)
+				return &pb.StreamResult{ErrorMessage: "redundant and backup sources are mutually exclusive"}, false
 			}
+			dscp, err := streamDSCP(in)
+			if err != nil {
+				log.WithError(err).Errorf("Stream with ID %d has an invalid DSCP", in.Id)
+				return &pb.StreamResult{ErrorMessage: err.Error()}, false
+			}
+			var nack *nackCache
+			if in.NackCacheDepth > 0 {
+				if nack, err = newNACKCache(in.NackCacheDepth); err != nil {
+					log.WithError(err).Errorf("Stream with ID %d has an invalid NACK cache", in.Id)
+					return &pb.StreamResult{ErrorMessage: err.Error()}, false
+				}
+			}
+			stream := &Stream{
+				sources: []*source{newSource(in.Endpoint.Ip, in.Endpoint.Port)},
+				clients: make(map[string]*Endpoint),
+				stats:   newCounters(),
+				created: time.Now(),
+
+				idleTimeout:   time.Duration(in.IdleTimeoutMs) * time.Millisecond,
+				removeTimeout: time.Duration(in.IdleRemoveMs) * time.Millisecond,
+
+				clientTimeout:      time.Duration(in.ClientTimeoutMs) * time.Millisecond,
+				disableDeadClients: in.DisableDeadClients,
+
+				dscp:       dscp,
+				pacingKbps: in.PacingKbps,
+				maxKbps:    in.MaxKbps,
+				codec:      in.Codec,
+				nack:       nack,
+			}
+			for _, redundant := range in.RedundantSources {
+				stream.sources = append(stream.sources, newSource(redundant.Ip, redundant.Port))
+			}
+			if len(in.RedundantSources) > 0 {
+				stream.merger = &seqMerger{}
+			}
+			for _, backup := range in.BackupSources {
+				stream.sources = append(stream.sources, newSource(backup.Ip, backup.Port))
+			}
+			endpoints := append(append([]*pb.Endpoint{in.Endpoint}, in.RedundantSources...), in.BackupSources...)
+			for i, endpoint := range endpoints {
+				if stream.sources[i].srtp, err = endpointSRTP(endpoint); err != nil {
+					log.WithError(err).Errorf("Source %v of stream %v has invalid SRTP parameters", stream.sources[i].address, in.Id)
+					return &pb.StreamResult{ErrorMessage: err.Error()}, false
+				}
+			}
+			if stream.sources[0].address.IP.IsMulticast() {
+				if len(stream.sources) > 1 {
+					log.Errorf("Stream with ID %d can't have a multicast source and other sources", in.Id)
+					return &pb.StreamResult{ErrorMessage: "a multicast source can't have redundant or backup sources"}, false
+				}
+				receiver, err := newMulticastReceiver(stream.sources[0].address, in.MulticastSource, in.MulticastInterface)
+				if err != nil {
+					log.WithError(err).Errorf("Could not join multicast group of stream %v", in.Id)
+					return &pb.StreamResult{ErrorMessage: err.Error()}, false
+				}
+				stream.ingress = receiver
+				s.receiveMulticast(in.Id, stream)
+				log.Infof("Stream ID: %v joined multicast group %v", in.Id, stream.sources[0].address)
+			}
+			if len(in.BackupSources) > 0 {
+				stream.failoverTimeout = time.Duration(in.FailoverTimeoutMs) * time.Millisecond
+				if stream.failoverTimeout == 0 {
+					stream.failoverTimeout = defaultFailoverTimeout
+				}
+			}
+			if in.CacheGop {
+				stream.gop = newGOPCache(in.Codec)
+			}
+			if in.RewriteSsrc {
+				stream.rewriter = newRTPRewriter(in.ClockRate)
+			}
+			if in.TerminateRtcp {
+				stream.translator = newRTCPTranslator(in.Id, in.ClockRate)
+			}
+			streams[in.Id] = stream
 			log.Infof("New stream ID: %v, source %v:%v", in.Id, in.Endpoint.Ip, in.Endpoint.Port)
-			streamMap[fmt.Sprintf("%s:%d", in.Endpoint.Ip, in.Endpoint.Port)] = in.Id
+			for _, src := range stream.sources {
+				streamMap[addressKey(src.address)] = in.Id
+			}
+			if stream.merger != nil {
+				log.Infof("Stream ID: %v merges %d redundant sources", in.Id, len(stream.sources))
+			} else if stream.failoverTimeout > 0 {
+				log.Infof("Stream ID: %v has %d backup sources, failover after %v", in.Id, len(in.BackupSources), stream.failoverTimeout)
+			}
 		} else {
 			log.Errorf("Stream with ID %d already exists", in.Id)
+			return &pb.StreamResult{}, false
 		}
 	case "UPDATE":
-		log.Errorf("unexpected UPDATE")
+		// move the stream to a different source
+		stream, ok := streams[in.Id]
+		if !ok {
+			log.Errorf("Stream with ID %d doesn't exists", in.Id)
+			return &pb.StreamResult{}, false
+		}
+		// the receivers of multicast sources and the merger of redundant
+		// sources are set up for the sources they were created with
+		if stream.ingress != nil || stream.merger != nil {
+			log.Errorf("Source of stream %v with a multicast or redundant sources can't be moved", in.Id)
+			return &pb.StreamResult{ErrorMessage: "streams with a multicast or redundant sources must be recreated to change their source"}, false
+		}
+		if address := parseAddress(in.Endpoint.Ip, in.Endpoint.Port); address.IP.IsMulticast() {
+			log.Errorf("Stream %v can't be moved to multicast source %v", in.Id, address)
+			return &pb.StreamResult{ErrorMessage: "streams must be recreated to change to a multicast source"}, false
+		}
+		srtp, err := endpointSRTP(in.Endpoint)
+		if err != nil {
+			log.WithError(err).Errorf("Source of stream %v has invalid SRTP parameters", in.Id)
+			return &pb.StreamResult{ErrorMessage: err.Error()}, false
+		}
+		delete(streamMap, addressKey(stream.sources[0].address))
+		stream.sources[0] = newSource(in.Endpoint.Ip, in.Endpoint.Port)
+		stream.sources[0].srtp = srtp
+		streamMap[addressKey(stream.sources[0].address)] = in.Id
+		stream.activate(0)
+		log.Infof("Stream ID: %v moved to source %v:%v", in.Id, in.Endpoint.Ip, in.Endpoint.Port)
 	case "DELETE":
-		delete(streams, in.Id)
+		deleteStream(in.Id)
 		log.Infof("Deleted stream ID: %v", in.Id)
 	default:
-		client := net.UDPAddr{IP: net.ParseIP(in.Endpoint.Ip), Port: int(in.Endpoint.Port), Zone: ""}
+		client := parseAddress(in.Endpoint.Ip, in.Endpoint.Port)
+		key := clientKey(in)
 		stream, ok := streams[in.Id]
 		if !ok {
 			log.Errorf("Stream with ID %d doesn't exists", in.Id)
-			return &pb.StreamResult{}, nil
+			return &pb.StreamResult{}, false
 		}
 		if in.Operation.String() == "ADD_EP" {
-			stream.clients[client.String()] = Endpoint{enabled: in.Enable, address: client}
-			streams[in.Id] = stream
+			// latched clients are checked when their address is learned
+			if in.Latch == pb.LatchMode_LATCH_NONE {
+				if err := checkDestination(client); err != nil {
+					log.WithError(err).Errorf("Client %v rejected from stream %v", client, in.Id)
+					return &pb.StreamResult{ErrorMessage: err.Error()}, false
+				}
+			}
+			dscp, err := clientDSCP(in, stream)
+			if err != nil {
+				log.WithError(err).Errorf("Client %v in stream %v has an invalid DSCP", client, in.Id)
+				return &pb.StreamResult{ErrorMessage: err.Error()}, false
+			}
+			srtp, err := endpointSRTP(in.Endpoint)
+			if err != nil {
+				log.WithError(err).Errorf("Client %v in stream %v has invalid SRTP parameters", client, in.Id)
+				return &pb.StreamResult{ErrorMessage: err.Error()}, false
+			}
+			endpoint := &Endpoint{enabled: in.Enable, address: client, replay: stream.gop != nil, added: time.Now(), dscp: dscp, srtp: srtp}
+			if srtp != nil && stream.nack != nil {
+				endpoint.srtpSent = stream.nack.clone()
+			}
+			if in.Latch != pb.LatchMode_LATCH_NONE {
+				// not forwarded to until the client's address is learned
+				endpoint.enabled = false
+				endpoint.latch = newLatch(in, time.Now())
+			}
+			if client.IP.IsMulticast() {
+				if endpoint.latch != nil {
+					log.Errorf("Multicast client %v in stream %v can't be latched", client, in.Id)
+					return &pb.StreamResult{ErrorMessage: "multicast clients can't be latched"}, false
+				}
+				sender, err := newMulticastSender(client, in.MulticastTtl, in.MulticastInterface)
+				if err != nil {
+					log.WithError(err).Errorf("Could not add multicast client %v to stream %v", client, in.Id)
+					return &pb.StreamResult{ErrorMessage: err.Error()}, false
+				}
+				endpoint.multicast = sender
+			}
+			if maxKbps := cmp.Or(in.MaxKbps, stream.maxKbps); maxKbps > 0 {
+				endpoint.limit = newTokenBucket(maxKbps, capBurst, time.Now())
+			}
+			if pacingKbps := cmp.Or(in.PacingKbps, stream.pacingKbps); pacingKbps > 0 {
+				endpoint.pacer = newPacer(pacingKbps)
+			}
+			if existing, ok := stream.clients[key]; ok {
+				existing.close()
+			}
+			stream.clients[key] = endpoint
 			log.Infof("Client %v added to stream %v", client, in.Id)
 		} else if in.Operation.String() == "UPD_EP" {
-			endpoint, ok := stream.clients[client.String()]
+			endpoint, ok := stream.clients[key]
 			if !ok {
 				log.Errorf("Endpoint %v doesn't exist in the stream %v", client, in.Id)
-				return &pb.StreamResult{}, nil
+				return &pb.StreamResult{}, false
+			}
+			if endpoint.latch != nil && !endpoint.latch.latched {
+				endpoint.latch.enable = in.Enable
+			} else {
+				if in.Enable && !endpoint.enabled {
+					endpoint.replay = stream.gop != nil
+					endpoint.revive(time.Now())
+				}
+				endpoint.enabled = in.Enable
 			}
-			endpoint.enabled = in.Enable
-			stream.clients[client.String()] = endpoint
-			streams[in.Id] = stream
 			log.Infof("Client %v updated in stream %v", client, in.Id)
 		} else if in.Operation.String() == "DEL_EP" {
-			_, ok := stream.clients[client.String()]
+			endpoint, ok := stream.clients[key]
 			if !ok {
 				log.Errorf("Endpoint %v doesn't exist in the stream %v", client, in.Id)
-				return &pb.StreamResult{}, nil
+				return &pb.StreamResult{}, false
 			}
-			delete(stream.clients, client.String())
-			streams[in.Id] = stream
+			endpoint.close()
+			delete(stream.clients, key)
 			log.Infof("Client %v deleted from stream %v", client, in.Id)
 		}
 	}
-	return &pb.StreamResult{}, nil
+	return &pb.StreamResult{Success: true}, true
+}
+
+// deleteStream removes a stream and its sources from the maps.
+// Must be called with streamsLock held.
+func deleteStream(streamID uint32) {
+	if stream, ok := streams[streamID]; ok {
+		if stream.stalled {
+			notifyStatus()
+		}
+		for _, src := range stream.sources {
+			delete(streamMap, addressKey(src.address))
+		}
+		for _, endpoint := range stream.clients {
+			endpoint.close()
+		}
+		if stream.ingress != nil {
+			stream.ingress.close()
+		}
+	}
+	delete(streams, streamID)
 }
 
-func forwardRTPPackets(port uint16) {
-	sourceAddr := &net.UDPAddr{IP: net.ParseIP("0.0.0.0"), Port: int(port), Zone: ""}
-	sourceConn, err := net.ListenUDP("udp", sourceAddr)
+func (s *server) StreamStats(_ context.Context, in *pb.StreamStatsRequest) (*pb.StreamStats, error) {
+	streamsLock.RLock()
+	defer streamsLock.RUnlock()
+
+	stream, ok := streams[in.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "stream with ID %d doesn't exist", in.Id)
+	}
+	counters := stream.stats.snapshot()
+	for name, value := range stream.sourceCounters() {
+		counters[name] = value
+	}
+	return &pb.StreamStats{Id: in.Id, Counters: counters}, nil
+}
+
+func listenUDP(port uint16, protocol string) *net.UDPConn {
+	if *listenAddress != "" {
+		sourceConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(*listenAddress), Port: int(port)})
+		if err != nil {
+			log.WithError(err).Fatalf("Could not start listening on %s port.", protocol)
+		}
+		return sourceConn
+	}
+	// listen on IPv6 and IPv4, or on IPv4 only if IPv6 is disabled on the node
+	sourceConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv6unspecified, Port: int(port), Zone: ""})
+	if err != nil {
+		sourceConn, err = net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero, Port: int(port), Zone: ""})
+	}
 	if err != nil {
-		log.WithError(err).Fatal("Could not start listening on RTP port.")
+		log.WithError(err).Fatalf("Could not start listening on %s port.", protocol)
 	}
+	return sourceConn
+}
+
+func forwardRTPPackets(sourceConn *net.UDPConn) {
 	defer func(sourceConn *net.UDPConn) {
 		err := sourceConn.Close()
-		if err != nil {
+		if err != nil && !errors.Is(err, net.ErrClosed) {
 			log.WithError(err).Warn("Unable to close sourceConn")
 		}
 	}(sourceConn)
 	buffer := make([]byte, 65507)
 	for {
 		n, sourceAddr, err := sourceConn.ReadFromUDP(buffer)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			// paused by a handoff
+			if waitReads() {
+				continue
+			}
+			return
+		}
 		if err != nil {
-			log.WithError(err).Warn("Error while reading RTP packet.")
+			if entry := ingressLog.entry("rtp-read-error"); entry != nil {
+				entry.WithError(err).Warn("Error while reading RTP packet.")
+			}
+			// ICMP errors for the forwarded packets are reported as read errors
+			if unreachable := readErrorQueue(sourceConn); len(unreachable) > 0 {
+				streamsLock.Lock()
+				for _, address := range unreachable {
+					clientUnreachable(address)
+				}
+				streamsLock.Unlock()
+			}
 			continue
 		}
+		streamsLock.Lock()
+		if acceptIngress(buffer[0:n], sourceAddr, false) {
+			forwardRTPPacket(sourceConn, buffer[0:n], sourceAddr)
+		}
+		streamsLock.Unlock()
+	}
+}
 
-		streamID, ok := streamMap[fmt.Sprintf("%s:%d", sourceAddr.IP.String(), sourceAddr.Port)]
-		if !ok {
-			log.Tracef("RTP stream for server %s:%d not found", sourceAddr.IP.String(), sourceAddr.Port)
-			continue
+// forwardRTPPacket sends an RTP packet to all enabled clients of its stream,
+// preceded by the cached GOP for clients that have just joined.
+// Must be called with streamsLock held.
+func forwardRTPPacket(conn *net.UDPConn, packet []byte, sourceAddr *net.UDPAddr) {
+	streamID, ok := streamMap[addressKey(*sourceAddr)]
+	if !ok {
+		if latchClient(packet, sourceAddr, false) {
+			return
 		}
-		stream, ok := streams[streamID]
-		if !ok {
-			log.Errorf("stream %v doesn't exists", streamID)
-			continue
+		log.Tracef("RTP stream for server %v not found", sourceAddr)
+		return
+	}
+	stream, ok := streams[streamID]
+	if !ok {
+		log.Errorf("stream %v doesn't exists", streamID)
+		return
+	}
+
+	src := stream.findSource(sourceAddr, false)
+	if src == nil {
+		if entry := ingressLog.entry("unknown-rtp-server"); entry != nil {
+			entry.Errorf("RTP packet received from unknown server %v, expected %v", sourceAddr, stream.sources[0].address)
 		}
+		return
+	}
+	forwardStreamRTP(conn, streamID, stream, src, packet)
+}
 
-		if !sourceAddr.IP.Equal(stream.server.IP) || sourceAddr.Port != stream.server.Port {
-			log.Errorf("RTP packet received from unknown server %v, expected %v", sourceAddr, stream.server)
-			continue
+// forwardStreamRTP forwards an RTP packet received from one of the stream's sources.
+// Must be called with streamsLock held.
+func forwardStreamRTP(conn *net.UDPConn, streamID uint32, stream *Stream, src *source, packet []byte) {
+	// the header is read below, ingress is only checked in strict mode
+	if _, ok := rtpHeaderLength(packet); !ok {
+		stream.stats.add("malformed_packets", 1)
+		log.Tracef("Dropped malformed RTP packet of stream %v from %v", streamID, src.address)
+		return
+	}
+	if src.srtp != nil {
+		var err error
+		if packet, err = src.srtp.unprotectRTP(packet); err != nil {
+			stream.stats.add(srtpCounter(err), 1)
+			log.WithError(err).Tracef("Dropped SRTP packet of stream %v", streamID)
+			return
 		}
+	}
+	now := time.Now()
+	src.lastPacket = now
+	if src.bye {
+		sourceRestarted(streamID, stream, src)
+	}
+	if stream.failoverTimeout > 0 && src != stream.sources[stream.active] {
+		// standby source, only its liveness is tracked
+		return
+	}
+	if stream.merger != nil {
+		src.stats.update(rtpSequence(packet), rtpTimestamp(packet), now, defaultClockRate)
+		if !stream.merger.accept(rtpSequence(packet)) {
+			stream.stats.add("duplicates", 1)
+			return
+		}
+	}
+	stream.lastPacket = now
+	if stream.stalled {
+		stream.stalled = false
+		notifyStatus()
+		log.Infof("Stream %v resumed", streamID)
+		events.publish(endpointEvent(streamID, pb.StreamEventType_STREAM_RESUMED, src.address, ""))
+	}
+	if stream.translator != nil {
+		stream.translator.received(packet, now)
+	}
+	if stream.rewriter != nil {
+		stream.rewriter.rewrite(packet, now)
+	}
+	if stream.translator != nil {
+		stream.translator.forwarded(packet, now)
+	}
+	stream.stats.add("rtp_packets", 1)
+	if stream.nack != nil {
+		stream.nack.store(packet)
+	}
+	cached := stream.gop != nil && stream.gop.add(packet)
 
-		for _, endpoint := range stream.clients {
-			if endpoint.enabled {
-				if _, err := sourceConn.WriteToUDP(buffer[0:n], &endpoint.address); err != nil {
-					log.WithError(err).Warn("Could not forward RTP packet.")
-				} else {
-					log.Tracef("RTP packet sent to %v", endpoint.address)
-				}
+	for _, endpoint := range stream.clients {
+		if !endpoint.enabled {
+			continue
+		}
+		packets := [][]byte{packet}
+		if endpoint.replay {
+			endpoint.replay = false
+			if cached {
+				packets = stream.gop.packets
+				log.Debugf("Replaying %d cached packets to %v", len(packets), endpoint.address)
 			}
 		}
+		for _, p := range packets {
+			endpoint.sendRTP(conn, stream, p, now)
+		}
 	}
 }
 
-func forwardRTCPPackets(port uint16) {
-	sourceAddr := &net.UDPAddr{IP: net.ParseIP("0.0.0.0"), Port: int(port), Zone: ""}
-	sourceConn, err := net.ListenUDP("udp", sourceAddr)
-	if err != nil {
-		log.WithError(err).Fatal("Could not start listening on RTCP port.")
-	}
+func forwardRTCPPackets(sourceConn *net.UDPConn, rtpConn *net.UDPConn) {
 	defer func(sourceConn *net.UDPConn) {
 		err := sourceConn.Close()
-		if err != nil {
+		if err != nil && !errors.Is(err, net.ErrClosed) {
 			log.WithError(err).Warn("Unable to close sourceConn")
 		}
 	}(sourceConn)
 	buffer := make([]byte, 65507)
 	for {
 		n, sourceAddr, err := sourceConn.ReadFromUDP(buffer)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			// paused by a handoff
+			if waitReads() {
+				continue
+			}
+			return
+		}
 		if err != nil {
-			log.WithError(err).Warn("Error while reading RTCP packet.")
+			if entry := ingressLog.entry("rtcp-read-error"); entry != nil {
+				entry.WithError(err).Warn("Error while reading RTCP packet.")
+			}
 			continue
 		}
+		streamsLock.Lock()
+		if acceptIngress(buffer[0:n], sourceAddr, true) {
+			forwardRTCPPacket(sourceConn, rtpConn, buffer[0:n], sourceAddr)
+		}
+		streamsLock.Unlock()
+	}
+}
 
-		streamID, ok := streamMap[fmt.Sprintf("%s:%d", sourceAddr.IP.String(), sourceAddr.Port-1)]
-		if !ok {
-			log.Tracef("RTCP stream for server %s:%d not found", sourceAddr.IP.String(), sourceAddr.Port)
-			continue
+// forwardRTCPPacket sends an RTCP packet from a source to all enabled clients of its stream.
+// RTCP packets from clients are handled locally.
+// Must be called with streamsLock held.
+func forwardRTCPPacket(conn *net.UDPConn, rtpConn *net.UDPConn, packet []byte, sourceAddr *net.UDPAddr) {
+	streamID, ok := streamMap[addressKey(net.UDPAddr{IP: sourceAddr.IP, Port: sourceAddr.Port - 1, Zone: sourceAddr.Zone})]
+	if !ok {
+		if handleClientRTCP(rtpConn, packet, sourceAddr) || latchClient(packet, sourceAddr, true) {
+			return
 		}
-		stream, ok := streams[streamID]
-		if !ok {
-			log.Errorf("stream %v doesn't exists", streamID)
-			continue
+		log.Tracef("RTCP stream for server %v not found", sourceAddr)
+		return
+	}
+	stream, ok := streams[streamID]
+	if !ok {
+		log.Errorf("stream %v doesn't exists", streamID)
+		return
+	}
+
+	src := stream.findSource(sourceAddr, true)
+	if src == nil {
+		if entry := ingressLog.entry("unknown-rtcp-server"); entry != nil {
+			entry.Errorf("RTCP packet received from unknown server %v, expected %v", sourceAddr, stream.sources[0].address)
 		}
+		return
+	}
+	forwardStreamRTCP(conn, streamID, stream, src, packet)
+}
 
-		if !sourceAddr.IP.Equal(stream.server.IP) || sourceAddr.Port != stream.server.Port+1 {
-			log.Errorf("RTCP packet received from unknown server %v, expected %v", sourceAddr, stream.server)
-			continue
+// forwardStreamRTCP forwards an RTCP packet received from one of the stream's sources.
+// Must be called with streamsLock held.
+func forwardStreamRTCP(conn *net.UDPConn, streamID uint32, stream *Stream, src *source, packet []byte) {
+	if stream.failoverTimeout > 0 && src != stream.sources[stream.active] {
+		return
+	}
+	if src.srtp != nil {
+		var err error
+		if packet, err = src.srtp.unprotectRTCP(packet); err != nil {
+			stream.stats.add(srtpCounter(err), 1)
+			log.WithError(err).Tracef("Dropped SRTCP packet of stream %v", streamID)
+			return
 		}
+	}
+
+	if stream.rewriter != nil {
+		stream.rewriter.rewriteRTCP(packet)
+	}
+	bye, reason := findBYE(packet)
+	ended := bye && sourceBYE(streamID, stream, src, reason)
+	if stream.translator != nil {
+		// RTCP is terminated, clients get sender reports generated by msm-dp
+		stream.translator.sourceRTCP(packet, time.Now())
+		if ended {
+			sendTranslatorBYE(conn, stream, time.Now())
+		}
+		return
+	}
+	if bye && !ended {
+		// the stream goes on from its other sources
+		return
+	}
 
+	for _, endpoint := range stream.clients {
+		if endpoint.enabled {
+			if err := endpoint.writeRTCP(conn, packet); err != nil {
+				log.WithError(err).Warn("Could not forward RTCP packet.")
+			} else {
+				log.Tracef("RTP packet sent to %v", endpoint.address)
+			}
+		}
+	}
+}
+
+// handleClientRTCP processes an RTCP packet received from a client and reports
+// whether the sender is a known client. Generic NACKs are answered from the
+// stream's retransmission cache.
+// Must be called with streamsLock held.
+func handleClientRTCP(rtpConn *net.UDPConn, packet []byte, clientAddr *net.UDPAddr) bool {
+	found := false
+	for streamID, stream := range streams {
 		for _, endpoint := range stream.clients {
-			if endpoint.enabled {
-				RTCPAddress := net.UDPAddr{IP: endpoint.address.IP, Port: endpoint.address.Port + 1, Zone: endpoint.address.Zone}
-				if _, err := sourceConn.WriteToUDP(buffer[0:n], &RTCPAddress); err != nil {
-					log.WithError(err).Warn("Could not forward RTCP packet.")
-				} else {
-					log.Tracef("RTP packet sent to %v", endpoint.address)
+			if !sameAddress(endpoint.rtcpAddress(), *clientAddr) {
+				continue
+			}
+			found = true
+			rtcp := packet
+			if endpoint.srtp != nil {
+				var err error
+				if rtcp, err = endpoint.srtp.unprotectRTCP(packet); err != nil {
+					stream.stats.add(srtpCounter(err), 1)
+					continue
 				}
 			}
+			endpoint.lastRTCP = time.Now()
+			endpoint.unreachable = 0
+			if endpoint.enabled {
+				endpoint.dead = false
+			}
+			forEachRTCP(rtcp, func(packetType, count byte, p []byte) {
+				switch {
+				case packetType == rtcpRTPFB && count == 1 && endpoint.enabled:
+					retransmit(rtpConn, streamID, stream, endpoint, genericNACKs(p))
+				case packetType == rtcpBYE && endpoint.enabled:
+					clientBYE(streamID, stream, endpoint, byeReason(p, count))
+				}
+			})
 		}
 	}
+	return found
 }
 
-func main() {
-	log.SetOutput(os.Stdout)
-	logFormat := os.Getenv("LOG_TYPE")
-	switch strings.ToLower(logFormat) {
-	case "json":
-		log.SetFormatter(&log.JSONFormatter{
-			TimestampFormat: "2006-01-02 15:04:05",
-			PrettyPrint:     true,
-		})
-	default:
-		log.SetFormatter(&log.TextFormatter{
-			ForceColors:     true,
-			DisableColors:   false,
-			FullTimestamp:   true,
-			TimestampFormat: "01-02-2006 15:04:05",
-		})
-	}
-
-	logLevel := os.Getenv("LOG_LEVEL")
-	switch strings.ToLower(logLevel) {
-	case "trace":
-		log.SetLevel(log.TraceLevel)
-	case "debug":
-		log.SetLevel(log.DebugLevel)
-	case "info":
-		log.SetLevel(log.InfoLevel)
-	case "warn":
-		log.SetLevel(log.WarnLevel)
-	case "error":
-		log.SetLevel(log.ErrorLevel)
-	default:
-		log.SetLevel(log.DebugLevel)
+// retransmit resends the packets reported lost by a client from the stream's NACK cache.
+func retransmit(rtpConn *net.UDPConn, streamID uint32, stream *Stream, endpoint *Endpoint, lost []uint16) {
+	stream.stats.add("nack_received", uint64(len(lost)))
+	if stream.nack == nil {
+		return
 	}
+	for _, seq := range lost {
+		packet, ok := stream.nack.get(seq)
+		sent := packet
+		if ok && endpoint.srtp != nil {
+			// protecting the packet again would reuse its SRTP index
+			sent, ok = endpoint.srtpSent.get(seq)
+		}
+		if !ok {
+			stream.stats.add("retransmit_misses", 1)
+			log.Tracef("RTP packet %d of stream %v not in NACK cache", seq, streamID)
+			continue
+		}
+		if !endpoint.resendRTP(rtpConn, stream, packet, sent, time.Now()) {
+			continue
+		}
+		endpoint.sent(packet)
+		stream.stats.add("retransmits", 1)
+		log.Tracef("RTP packet %d retransmitted to %v", seq, endpoint.address)
+	}
+}
 
+func main() {
+	log.SetOutput(os.Stdout)
 	flag.Parse()
+	if *configFile != "" {
+		if err := loadConfig(*configFile); err != nil {
+			log.WithError(err).Fatal("Invalid config file.")
+		}
+	}
+	if err := applySettings(); err != nil {
+		log.WithError(err).Fatal("Invalid configuration.")
+	}
+	if *configFile != "" {
+		log.Infof("Loaded config %s", *configFile)
+		go watchConfig(*configFile)
+	}
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+	previous, err := takeOver(*handoffSocket)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		log.WithError(err).Fatal("Could not take over from the running msm-dp.")
+	}
+	var lis net.Listener
+	var rtpConn, rtcpConn *net.UDPConn
+	if previous != nil {
+		lis, rtpConn, rtcpConn = previous.lis, previous.rtpConn, previous.rtcpConn
+	} else {
+		lis, err = net.Listen("tcp", net.JoinHostPort(*listenAddress, strconv.Itoa(*port)))
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+		rtpConn = listenUDP(uint16(*rtpPort), "RTP")
+		rtcpConn = listenUDP(uint16(*rtpPort+1), "RTCP")
 	}
-
-	s := grpc.NewServer()
-	pb.RegisterMsmDataPlaneServer(s, &server{})
 
 	healthService := NewHealthChecker()
+	healthService.setReady(componentRTPSocket, true)
+	if err := enableErrorQueue(rtpConn); err != nil {
+		log.WithError(err).Warn("Unreachable clients can't be detected.")
+	}
+	healthService.setReady(componentRTCPSocket, true)
+
+	opts, err := serverCredentials()
+	if err != nil {
+		log.WithError(err).Fatal("Could not load TLS credentials.")
+	}
+	if *authzPolicy != "" {
+		p, err := loadPolicy(*authzPolicy)
+		if err != nil {
+			log.WithError(err).Fatal("Could not load authorization policy.")
+		}
+		opts = append(opts, authorizationOptions(p)...)
+	}
+	s := grpc.NewServer(opts...)
+	dataPlane := &server{rtpConn: rtpConn, rtcpConn: rtcpConn, stopping: make(chan struct{})}
+	pb.RegisterMsmDataPlaneServer(s, dataPlane)
+	registry.file = *stateFile
+	if previous != nil {
+		registry.replay(dataPlane, previous.snapshot, "the previous msm-dp")
+	} else if *stateFile != "" {
+		if err := registry.restore(dataPlane); err != nil {
+			log.Fatalf("failed to restore streams from %s: %v", *stateFile, err)
+		}
+	}
+
+	go registry.saveChanges()
 	grpc_health_v1.RegisterHealthServer(s, healthService)
 
-	go forwardRTPPackets(uint16(*rtpPort))
-	go forwardRTCPPackets(uint16(*rtpPort + 1))
+	go healthService.run(componentRTPForwarder, func() { forwardRTPPackets(rtpConn) })
+	go healthService.run(componentRTCPForwarder, func() { forwardRTCPPackets(rtcpConn, rtpConn) })
+	go sendRTCPReports(rtcpConn)
+	go monitorStreams()
+
+	if previous != nil {
+		if err := previous.complete(); err != nil {
+			log.WithError(err).Fatal("Could not complete the takeover.")
+		}
+	}
+	handedOff := make(chan struct{})
+	if *handoffSocket != "" {
+		handoffListener, err := listenHandoff(*handoffSocket)
+		if err != nil {
+			log.WithError(err).Warnf("Could not listen for handoffs at %s.", *handoffSocket)
+		} else {
+			go func() {
+				if dataPlane.serveHandoff(handoffListener, lis) {
+					close(handedOff)
+				}
+			}()
+		}
+	}
 
 	log.Info("Listening for CP messages at ", lis.Addr())
+	healthService.setReady(componentControlServer, true)
+
+	signals, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-signals.Done():
+			dataPlane.shutdown(s, healthService)
+		case <-handedOff:
+			healthService.setReady(componentControlServer, false)
+			healthService.stop()
+			dataPlane.stopControl(s)
+		}
+		close(stopped)
+	}()
 
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
-
-	defer func(lis net.Listener) {
-		err := lis.Close()
-		if err != nil {
-			log.Fatalf("failed to close connection with CP: %v", err)
-		}
-	}(lis)
+	<-stopped
+	if err := registry.save(); err != nil {
+		log.WithError(err).Error("Could not save streams")
+	}
+	closeDataPlane(rtpConn, rtcpConn)
+	log.Info("Shut down")
 }
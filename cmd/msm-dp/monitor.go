@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// monitorInterval is how often the streams' sources are checked for liveness.
+	monitorInterval        = 50 * time.Millisecond
+	defaultFailoverTimeout = 500 * time.Millisecond
+)
+
+// monitorStreams periodically checks the liveness of the streams, their sources and clients.
+func monitorStreams() {
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		streamsLock.Lock()
+		for streamID, stream := range streams {
+			if stream.failoverTimeout > 0 {
+				checkFailover(streamID, stream, now)
+			}
+			checkLatches(streamID, stream, now)
+			if stream.clientTimeout > 0 {
+				checkClients(streamID, stream, now)
+			}
+			if stream.idleTimeout > 0 || stream.removeTimeout > 0 {
+				checkIdle(streamID, stream, now)
+			}
+		}
+		streamsLock.Unlock()
+		monitorHeartbeat.Store(now.UnixNano())
+	}
+}
+
+// alive reports whether the source has sent a packet within the timeout.
+func (s *source) alive(now time.Time, timeout time.Duration) bool {
+	return !s.lastPacket.IsZero() && now.Sub(s.lastPacket) <= timeout
+}
+
+// checkFailover promotes the next live source of the stream when the active
+// source is dead or has left.
+func checkFailover(streamID uint32, stream *Stream, now time.Time) {
+	active := stream.sources[stream.active]
+	if active.bye {
+		failover(streamID, stream, now, fmt.Sprintf("%v left", active.address))
+		return
+	}
+	lastPacket := active.lastPacket
+	if lastPacket.IsZero() {
+		lastPacket = stream.created
+	}
+	if now.Sub(lastPacket) <= stream.failoverTimeout {
+		return
+	}
+	failover(streamID, stream, now, fmt.Sprintf("no packets from %v for %v", active.address, now.Sub(lastPacket).Round(time.Millisecond)))
+}
+
+// failover promotes the next live source of the stream that hasn't left, and
+// reports whether there was one. A stream that ended goes on from that source.
+func failover(streamID uint32, stream *Stream, now time.Time, message string) bool {
+	for i := 1; i < len(stream.sources); i++ {
+		next := (stream.active + i) % len(stream.sources)
+		if stream.sources[next].bye || !stream.sources[next].alive(now, stream.failoverTimeout) {
+			continue
+		}
+		stream.activate(next)
+		stream.ended = false
+		log.Warnf("Stream %v failed over to source %v: %s", streamID, stream.sources[next].address, message)
+		stream.stats.add("failovers", 1)
+		events.publish(endpointEvent(streamID, pb.StreamEventType_SOURCE_FAILOVER, stream.sources[next].address, message))
+		return true
+	}
+	return false
+}
+
+// activate makes the source at index the one whose packets are forwarded.
+func (s *Stream) activate(index int) {
+	s.active = index
+	if s.rewriter != nil {
+		s.rewriter.switchSource()
+	}
+	if s.gop != nil {
+		s.gop.reset()
+	}
+}
+
+// checkIdle marks the stream as stalled when no packets have been forwarded
+// for the idle timeout, and removes it after the remove timeout.
+func checkIdle(streamID uint32, stream *Stream, now time.Time) {
+	lastPacket := stream.lastPacket
+	if lastPacket.IsZero() {
+		lastPacket = stream.created
+	}
+	idle := now.Sub(lastPacket)
+	source := stream.sources[stream.active].address
+
+	if stream.removeTimeout > 0 && idle > stream.removeTimeout {
+		deleteStream(streamID)
+		registry.forgetStream(streamID)
+		message := fmt.Sprintf("no packets for %v", idle.Round(time.Millisecond))
+		log.Warnf("Stream %v removed: %s", streamID, message)
+		events.publish(endpointEvent(streamID, pb.StreamEventType_STREAM_REMOVED, source, message))
+		return
+	}
+	if stream.idleTimeout > 0 && idle > stream.idleTimeout && !stream.stalled && !stream.ended {
+		stream.stalled = true
+		notifyStatus()
+		stream.stats.add("stalls", 1)
+		message := fmt.Sprintf("no packets for %v", idle.Round(time.Millisecond))
+		log.Warnf("Stream %v stalled: %s", streamID, message)
+		events.publish(endpointEvent(streamID, pb.StreamEventType_STREAM_STALLED, source, message))
+	}
+}
+
+// stalledStreams returns the number of streams currently stalled.
+func stalledStreams() int {
+	streamsLock.RLock()
+	defer streamsLock.RUnlock()
+	stalled := 0
+	for _, stream := range streams {
+		if stream.stalled {
+			stalled++
+		}
+	}
+	return stalled
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestFailover(t *testing.T) {
+	now := time.Now()
+	primary := newSource("127.0.0.1", 7000)
+	backup1 := newSource("127.0.0.1", 7002)
+	backup2 := newSource("127.0.0.1", 7004)
+	stream := &Stream{
+		sources:         []*source{primary, backup1, backup2},
+		failoverTimeout: 500 * time.Millisecond,
+		created:         now,
+		stats:           newCounters(),
+	}
+
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	primary.lastPacket = now
+	backup2.lastPacket = now
+	checkFailover(4, stream, now.Add(400*time.Millisecond))
+	require.Equal(t, 0, stream.active, "primary is still alive")
+
+	backup2.lastPacket = now.Add(time.Second)
+	checkFailover(4, stream, now.Add(1100*time.Millisecond))
+	require.Equal(t, 2, stream.active, "dead backup is skipped")
+
+	event := <-ch
+	require.Equal(t, uint32(4), event.Id)
+	require.Equal(t, pb.StreamEventType_SOURCE_FAILOVER, event.Type)
+	require.Equal(t, uint32(7004), event.Endpoint.Port)
+
+	checkFailover(4, stream, now.Add(3*time.Second))
+	require.Equal(t, 2, stream.active, "no live source to fail over to")
+	require.Equal(t, uint64(1), stream.stats.snapshot()["failovers"])
+}
+
+func TestIdleStream(t *testing.T) {
+	now := time.Now()
+	src := newSource("127.0.0.1", 7100)
+	stream := &Stream{
+		sources:       []*source{src},
+		created:       now,
+		lastPacket:    now,
+		idleTimeout:   time.Second,
+		removeTimeout: 5 * time.Second,
+		stats:         newCounters(),
+	}
+	streamsLock.Lock()
+	streams[5] = stream
+	streamMap[addressKey(src.address)] = 5
+	streamsLock.Unlock()
+
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+	health := NewHealthChecker()
+	request := &grpc_health_v1.HealthCheckRequest{Service: streamsHealthService}
+
+	streamsLock.Lock()
+	checkIdle(5, stream, now.Add(2*time.Second))
+	checkIdle(5, stream, now.Add(3*time.Second))
+	streamsLock.Unlock()
+	require.True(t, stream.stalled)
+	require.Equal(t, pb.StreamEventType_STREAM_STALLED, (<-ch).Type)
+	require.Len(t, ch, 0, "stall is reported once")
+
+	response, err := health.Check(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+
+	streamsLock.Lock()
+	checkIdle(5, stream, now.Add(6*time.Second))
+	_, exists := streams[5]
+	_, mapped := streamMap[addressKey(src.address)]
+	streamsLock.Unlock()
+	require.False(t, exists)
+	require.False(t, mapped)
+	require.Equal(t, pb.StreamEventType_STREAM_REMOVED, (<-ch).Type)
+
+	response, err = health.Check(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// defaultMulticastTTL keeps multicast egress on the local network segment.
+const defaultMulticastTTL = 1
+
+// multicastSender holds the sockets used to send a stream to a multicast
+// group, with the TTL and outbound interface of the group.
+type multicastSender struct {
+	rtp  *net.UDPConn
+	rtcp *net.UDPConn
+}
+
+func newMulticastSender(group net.UDPAddr, ttl uint32, interfaceName string) (*multicastSender, error) {
+	if ttl == 0 {
+		ttl = defaultMulticastTTL
+	}
+	if ttl > 255 {
+		return nil, fmt.Errorf("invalid multicast TTL %d", ttl)
+	}
+	var ifi *net.Interface
+	if interfaceName != "" {
+		var err error
+		if ifi, err = net.InterfaceByName(interfaceName); err != nil {
+			return nil, err
+		}
+	}
+
+	sender := &multicastSender{}
+	var err error
+	if sender.rtp, err = multicastConn(group, int(ttl), ifi); err != nil {
+		return nil, err
+	}
+	if sender.rtcp, err = multicastConn(group, int(ttl), ifi); err != nil {
+		sender.close()
+		return nil, err
+	}
+	return sender, nil
+}
+
+func multicastConn(group net.UDPAddr, ttl int, ifi *net.Interface) (*net.UDPConn, error) {
+	if group.IP.To4() != nil {
+		conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero})
+		if err != nil {
+			return nil, err
+		}
+		p := ipv4.NewPacketConn(conn)
+		if err = p.SetMulticastTTL(ttl); err == nil && ifi != nil {
+			err = p.SetMulticastInterface(ifi)
+		}
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+
+	conn, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6unspecified})
+	if err != nil {
+		return nil, err
+	}
+	p := ipv6.NewPacketConn(conn)
+	if err = p.SetMulticastHopLimit(ttl); err == nil && ifi != nil {
+		err = p.SetMulticastInterface(ifi)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (m *multicastSender) close() {
+	for _, conn := range []*net.UDPConn{m.rtp, m.rtcp} {
+		if conn == nil {
+			continue
+		}
+		if err := conn.Close(); err != nil {
+			log.WithError(err).Warn("Unable to close multicast socket")
+		}
+	}
+}
+
+// multicastReceiver holds the sockets that receive a stream sent to a
+// multicast group. The group is joined when the stream is created and left
+// when it is deleted.
+type multicastReceiver struct {
+	rtp   *net.UDPConn
+	rtcp  *net.UDPConn
+	leave []func() error
+}
+
+// newMulticastReceiver joins a multicast group on its RTP and RTCP ports, for
+// any source or only for the given source-specific multicast source.
+func newMulticastReceiver(group net.UDPAddr, source string, interfaceName string) (*multicastReceiver, error) {
+	var sourceIP net.IP
+	if source != "" {
+		sourceAddr := parseAddress(source, 0)
+		if sourceAddr.IP == nil {
+			return nil, fmt.Errorf("invalid multicast source %q", source)
+		}
+		sourceIP = sourceAddr.IP
+	}
+	var ifi *net.Interface
+	if interfaceName != "" {
+		var err error
+		if ifi, err = net.InterfaceByName(interfaceName); err != nil {
+			return nil, err
+		}
+	}
+
+	receiver := &multicastReceiver{}
+	var err error
+	if receiver.rtp, err = receiver.join(group.IP, group.Port, ifi, sourceIP); err != nil {
+		return nil, err
+	}
+	if receiver.rtcp, err = receiver.join(group.IP, group.Port+1, ifi, sourceIP); err != nil {
+		receiver.close()
+		return nil, err
+	}
+	return receiver, nil
+}
+
+func (m *multicastReceiver) join(group net.IP, port int, ifi *net.Interface, source net.IP) (*net.UDPConn, error) {
+	groupAddr := &net.UDPAddr{IP: group}
+	if group.To4() != nil {
+		// bind to the group so that only its packets are received
+		conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: group, Port: port})
+		if err != nil {
+			return nil, err
+		}
+		p := ipv4.NewPacketConn(conn)
+		if source != nil {
+			sourceAddr := &net.UDPAddr{IP: source}
+			err = p.JoinSourceSpecificGroup(ifi, groupAddr, sourceAddr)
+			m.leave = append(m.leave, func() error { return p.LeaveSourceSpecificGroup(ifi, groupAddr, sourceAddr) })
+		} else {
+			err = p.JoinGroup(ifi, groupAddr)
+			m.leave = append(m.leave, func() error { return p.LeaveGroup(ifi, groupAddr) })
+		}
+		if err != nil {
+			m.leave = m.leave[:len(m.leave)-1]
+			_ = conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+
+	conn, err := net.ListenUDP("udp6", &net.UDPAddr{IP: group, Port: port})
+	if err != nil {
+		return nil, err
+	}
+	p := ipv6.NewPacketConn(conn)
+	if source != nil {
+		sourceAddr := &net.UDPAddr{IP: source}
+		err = p.JoinSourceSpecificGroup(ifi, groupAddr, sourceAddr)
+		m.leave = append(m.leave, func() error { return p.LeaveSourceSpecificGroup(ifi, groupAddr, sourceAddr) })
+	} else {
+		err = p.JoinGroup(ifi, groupAddr)
+		m.leave = append(m.leave, func() error { return p.LeaveGroup(ifi, groupAddr) })
+	}
+	if err != nil {
+		m.leave = m.leave[:len(m.leave)-1]
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// close leaves the multicast group and closes the sockets.
+func (m *multicastReceiver) close() {
+	for _, leave := range m.leave {
+		if err := leave(); err != nil {
+			log.WithError(err).Warn("Unable to leave multicast group")
+		}
+	}
+	for _, conn := range []*net.UDPConn{m.rtp, m.rtcp} {
+		if conn == nil {
+			continue
+		}
+		if err := conn.Close(); err != nil {
+			log.WithError(err).Warn("Unable to close multicast socket")
+		}
+	}
+}
+
+// receiveMulticast forwards the packets a stream receives from its multicast
+// group until the stream is deleted.
+func (s *server) receiveMulticast(streamID uint32, stream *Stream) {
+	receive := func(conn *net.UDPConn, forward func(packet []byte)) {
+		buffer := make([]byte, 65507)
+		for {
+			n, _, err := conn.ReadFromUDP(buffer)
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if err != nil {
+				log.WithError(err).Warn("Error while reading multicast packet.")
+				continue
+			}
+			streamsLock.Lock()
+			if streams[streamID] == stream {
+				forward(buffer[:n])
+			}
+			streamsLock.Unlock()
+		}
+	}
+
+	src := stream.sources[0]
+	go receive(stream.ingress.rtp, func(packet []byte) {
+		forwardStreamRTP(s.rtpConn, streamID, stream, src, packet)
+	})
+	go receive(stream.ingress.rtcp, func(packet []byte) {
+		forwardStreamRTCP(s.rtcpConn, streamID, stream, src, packet)
+	})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/ipv4"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestMulticastSender(t *testing.T) {
+	group := net.UDPAddr{IP: net.IPv4(239, 1, 2, 3), Port: 5004}
+	sender, err := newMulticastSender(group, 5, "")
+	require.NoError(t, err)
+	defer sender.close()
+	ttl, err := ipv4.NewPacketConn(sender.rtp).MulticastTTL()
+	require.NoError(t, err)
+	require.Equal(t, 5, ttl)
+
+	_, err = newMulticastSender(group, 256, "")
+	require.Error(t, err)
+	_, err = newMulticastSender(group, 0, "no-such-interface")
+	require.Error(t, err)
+}
+
+func TestMulticastClient(t *testing.T) {
+	s := &server{}
+	_, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 12, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7500},
+	})
+	require.NoError(t, err)
+	defer s.StreamAddDel(context.Background(), &pb.StreamData{Id: 12, Operation: pb.StreamOperation_DELETE})
+
+	group := &pb.Endpoint{Ip: "239.1.2.3", Port: 5004}
+	result, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 12, Operation: pb.StreamOperation_ADD_EP, Endpoint: group, Enable: true, MulticastTtl: 4,
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.ErrorMessage)
+
+	streamsLock.RLock()
+	endpoint := streams[12].clients["239.1.2.3:5004"]
+	streamsLock.RUnlock()
+	require.NotNil(t, endpoint.multicast)
+	rtcpConn := endpoint.multicast.rtcp
+
+	_, err = s.StreamAddDel(context.Background(), &pb.StreamData{Id: 12, Operation: pb.StreamOperation_DEL_EP, Endpoint: group})
+	require.NoError(t, err)
+	require.Error(t, rtcpConn.SetReadBuffer(1024), "sockets are closed with the client")
+}
+
+func TestMulticastSource(t *testing.T) {
+	rtpConn := listenLocalUDP(t)
+	client := listenLocalUDP(t)
+	clientAddr := client.LocalAddr().(*net.UDPAddr)
+
+	s := &server{rtpConn: rtpConn, rtcpConn: rtpConn}
+	group := &pb.Endpoint{Ip: "239.1.2.4", Port: 5020}
+	result, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 13, Operation: pb.StreamOperation_CREATE, Endpoint: group,
+	})
+	require.NoError(t, err)
+	if result.ErrorMessage != "" {
+		t.Skipf("multicast not available: %s", result.ErrorMessage)
+	}
+	defer s.StreamAddDel(context.Background(), &pb.StreamData{Id: 13, Operation: pb.StreamOperation_DELETE})
+	_, err = s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 13, Operation: pb.StreamOperation_ADD_EP, Enable: true,
+		Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: uint32(clientAddr.Port)},
+	})
+	require.NoError(t, err)
+
+	sender, err := newMulticastSender(net.UDPAddr{IP: net.IPv4(239, 1, 2, 4), Port: 5020}, 1, "")
+	require.NoError(t, err)
+	defer sender.close()
+	require.NoError(t, ipv4.NewPacketConn(sender.rtp).SetMulticastLoopback(true))
+	packet := rtpPacket(1, 100, 0x65)
+	_, err = sender.rtp.WriteToUDP(packet, &net.UDPAddr{IP: net.IPv4(239, 1, 2, 4), Port: 5020})
+	require.NoError(t, err)
+
+	buffer := make([]byte, 1500)
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	n, _, err := client.ReadFromUDP(buffer)
+	if err != nil {
+		t.Skipf("multicast loopback not available: %v", err)
+	}
+	require.Equal(t, packet, buffer[:n])
+
+	streamsLock.RLock()
+	ingress := streams[13].ingress
+	streamsLock.RUnlock()
+	_, err = s.StreamAddDel(context.Background(), &pb.StreamData{Id: 13, Operation: pb.StreamOperation_DELETE})
+	require.NoError(t, err)
+	require.Error(t, ingress.rtp.SetReadBuffer(1024), "group is left when the stream is deleted")
+
+	result, err = s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 13, Operation: pb.StreamOperation_CREATE, Endpoint: group, MulticastSource: "not-an-address",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.ErrorMessage)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// maxNACKCacheDepth bounds the packets cached per stream to answer NACKs, larger
+// depths are clamped to it.
+const maxNACKCacheDepth = 8192
+
+// nackCache is a ring buffer of recent RTP packets indexed by sequence number,
+// used to retransmit packets reported lost by clients.
+type nackCache struct {
+	packets [][]byte
+}
+
+// newNACKCache returns a cache of the depth rounded up to a power of two, so
+// that the slots of the sequence numbers don't shift when they wrap.
+func newNACKCache(depth uint32) (*nackCache, error) {
+	if depth == 0 {
+		return nil, errors.New("NACK cache depth must not be 0")
+	}
+	depth = min(depth, maxNACKCacheDepth)
+	depth = 1 << bits.Len32(depth-1)
+	return &nackCache{packets: make([][]byte, depth)}, nil
+}
+
+// clone returns an empty cache of the same depth.
+func (c *nackCache) clone() *nackCache {
+	return &nackCache{packets: make([][]byte, len(c.packets))}
+}
+
+func (c *nackCache) slot(seq uint16) int {
+	return int(seq) & (len(c.packets) - 1)
+}
+
+func (c *nackCache) store(packet []byte) {
+	if len(packet) < rtpHeaderLen {
+		return
+	}
+	slot := &c.packets[c.slot(rtpSequence(packet))]
+	*slot = append((*slot)[:0], packet...)
+}
+
+// get returns the cached packet with the given sequence number, if it is still in the cache.
+func (c *nackCache) get(seq uint16) ([]byte, bool) {
+	packet := c.packets[c.slot(seq)]
+	if len(packet) < rtpHeaderLen || rtpSequence(packet) != seq {
+		return nil, false
+	}
+	return packet, true
+}
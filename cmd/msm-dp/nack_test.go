@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNACKCache(t *testing.T) {
+	cache, err := newNACKCache(4)
+	require.NoError(t, err)
+	for seq := uint16(65533); seq != 3; seq++ {
+		cache.store(rtpPacket(seq, 0, byte(seq)))
+	}
+
+	_, ok := cache.get(65533)
+	require.False(t, ok, "oldest packet has been overwritten")
+	for _, seq := range []uint16{65535, 0, 1, 2} {
+		packet, ok := cache.get(seq)
+		require.True(t, ok)
+		require.Equal(t, seq, rtpSequence(packet))
+	}
+	_, ok = cache.get(3)
+	require.False(t, ok)
+}
+
+func TestNACKCacheDepth(t *testing.T) {
+	_, err := newNACKCache(0)
+	require.Error(t, err)
+	for depth, slots := range map[uint32]int{1: 1, 3: 4, 1000: 1024, 1024: 1024, 4_000_000_000: maxNACKCacheDepth} {
+		cache, err := newNACKCache(depth)
+		require.NoError(t, err)
+		require.Len(t, cache.packets, slots, depth)
+	}
+
+	// packets keep their slots across the sequence number wrap
+	cache, err := newNACKCache(1000)
+	require.NoError(t, err)
+	for seq := uint16(65000); seq != 500; seq++ {
+		cache.store(rtpPacket(seq, 0))
+	}
+	for seq := uint16(65535 - 500); seq != 500; seq++ {
+		_, ok := cache.get(seq)
+		require.True(t, ok, seq)
+	}
+}
+
+func TestGenericNACKs(t *testing.T) {
+	nack := []byte{
+		0x81, rtcpRTPFB, 0x00, 0x03,
+		0x00, 0x00, 0x00, 0x01,
+		0x12, 0x34, 0x56, 0x78,
+		0xff, 0xfe, 0x00, 0x05,
+	}
+	var lost []uint16
+	require.True(t, forEachRTCP(nack, func(packetType, count byte, packet []byte) {
+		require.Equal(t, byte(rtcpRTPFB), packetType)
+		require.Equal(t, byte(1), count)
+		lost = genericNACKs(packet)
+	}))
+	require.Equal(t, []uint16{65534, 65535, 1}, lost)
+}
+
+func TestClientNACKRetransmission(t *testing.T) {
+	rtpConn := listenLocalUDP(t)
+	client := listenLocalUDP(t)
+
+	clientAddr := client.LocalAddr().(*net.UDPAddr)
+	nackCache, err := newNACKCache(16)
+	require.NoError(t, err)
+	stream := &Stream{clients: make(map[string]*Endpoint), nack: nackCache, stats: newCounters()}
+	stream.clients[clientAddr.String()] = &Endpoint{enabled: true, address: *clientAddr}
+	for seq := uint16(100); seq < 110; seq++ {
+		stream.nack.store(rtpPacket(seq, 0))
+	}
+
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	streams[2] = stream
+	defer delete(streams, 2)
+
+	nack := []byte{
+		0x81, rtcpRTPFB, 0x00, 0x03,
+		0x00, 0x00, 0x00, 0x01,
+		0x12, 0x34, 0x56, 0x78,
+	}
+	nack = binary.BigEndian.AppendUint16(nack, 103)
+	nack = binary.BigEndian.AppendUint16(nack, 0x8001)
+	rtcpAddr := &net.UDPAddr{IP: clientAddr.IP, Port: clientAddr.Port + 1}
+	require.True(t, handleClientRTCP(rtpConn, nack, rtcpAddr))
+
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	buffer := make([]byte, 1500)
+	n, err := client.Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, uint16(103), rtpSequence(buffer[:n]))
+	n, err = client.Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, uint16(104), rtpSequence(buffer[:n]))
+
+	counters := stream.stats.snapshot()
+	require.Equal(t, uint64(3), counters["nack_received"])
+	require.Equal(t, uint64(2), counters["retransmits"])
+	require.Equal(t, uint64(1), counters["retransmit_misses"])
+}
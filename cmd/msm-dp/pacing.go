@@ -0,0 +1,235 @@
+package main
+
+import (
+	"net"
+	"time"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// pacingBurst is how much of its rate a paced client can be sent at once
+	pacingBurst = 10 * time.Millisecond
+	// capBurst is how much of its rate a capped client can be sent at once,
+	// enough for a keyframe above the average rate
+	capBurst = 500 * time.Millisecond
+	// minBurst lets a full size packet through the slowest buckets
+	minBurst = 1500
+	// maxPacedPackets bounds the packets queued for a paced client
+	maxPacedPackets = 1024
+)
+
+// tokenBucket measures the bytes sent to a client against a rate.
+type tokenBucket struct {
+	rate   float64 // bytes per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(kbps uint32, burst time.Duration, now time.Time) *tokenBucket {
+	rate := float64(kbps) * 1000 / 8
+	b := &tokenBucket{rate: rate, burst: max(rate*burst.Seconds(), minBurst), last: now}
+	b.tokens = b.burst
+	return b
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// allow takes the tokens for a packet if there are enough. Discardable packets
+// are only allowed while the bucket is more than half full, so that the rest
+// is kept for the packets other packets depend on.
+func (b *tokenBucket) allow(size int, discardable bool, now time.Time) bool {
+	b.refill(now)
+	needed := float64(size)
+	if discardable {
+		needed += b.burst / 2
+	}
+	if b.tokens < needed {
+		return false
+	}
+	b.tokens -= float64(size)
+	return true
+}
+
+// wait takes the tokens for a packet and returns how long to wait before
+// sending it.
+func (b *tokenBucket) wait(size int, now time.Time) time.Duration {
+	b.refill(now)
+	b.tokens -= float64(size)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// pacedPacket is a packet queued with everything needed to send it, so that
+// the pacer doesn't access the client.
+type pacedPacket struct {
+	conn    *net.UDPConn
+	address net.UDPAddr
+	oob     []byte
+	packet  []byte
+}
+
+// pacer sends the packets queued for a client at its pacing rate.
+type pacer struct {
+	bucket *tokenBucket
+	queue  chan pacedPacket
+	done   chan struct{}
+}
+
+func newPacer(kbps uint32) *pacer {
+	p := &pacer{
+		bucket: newTokenBucket(kbps, pacingBurst, time.Now()),
+		queue:  make(chan pacedPacket, maxPacedPackets),
+		done:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// enqueue queues a packet, or returns false if the queue is full.
+func (p *pacer) enqueue(packet pacedPacket) bool {
+	select {
+	case p.queue <- packet:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *pacer) run() {
+	timer := time.NewTimer(0)
+	<-timer.C
+	for {
+		select {
+		case <-p.done:
+			return
+		case packet := <-p.queue:
+			if delay := p.bucket.wait(len(packet.packet), time.Now()); delay > 0 {
+				timer.Reset(delay)
+				select {
+				case <-p.done:
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+			if _, _, err := packet.conn.WriteMsgUDP(packet.packet, packet.oob, &packet.address); err != nil {
+				log.WithError(err).Warn("Could not forward paced RTP packet.")
+			}
+		}
+	}
+}
+
+// stop discards the queued packets and stops the pacer.
+func (p *pacer) stop() {
+	close(p.done)
+}
+
+// sendRTP sends an RTP packet to the client within its bandwidth cap, through
+// its pacer if it has one.
+// Must be called with streamsLock held.
+func (e *Endpoint) sendRTP(conn *net.UDPConn, stream *Stream, packet []byte, now time.Time) {
+	if !e.admit(stream, packet, now) {
+		return
+	}
+	if e.pacer != nil {
+		paced := clonePacket(packet)
+		if e.srtp != nil {
+			var err error
+			if paced, err = e.protectRTP(packet); err != nil {
+				log.WithError(err).Warn("Could not protect RTP packet.")
+				return
+			}
+		}
+		if e.pace(conn, stream, paced) {
+			e.sent(packet)
+		}
+		return
+	}
+	if err := e.writeRTP(conn, packet); err != nil {
+		log.WithError(err).Warn("Could not forward RTP packet.")
+	} else {
+		e.sent(packet)
+		log.Tracef("RTP packet sent to %v", e.address)
+	}
+}
+
+// resendRTP retransmits an RTP packet to the client as it was sent, protected
+// if it is SRTP, within its bandwidth cap and through its pacer if it has one,
+// and reports whether it was sent.
+// Must be called with streamsLock held.
+func (e *Endpoint) resendRTP(conn *net.UDPConn, stream *Stream, packet, sent []byte, now time.Time) bool {
+	if !e.admit(stream, packet, now) {
+		return false
+	}
+	if e.pacer != nil {
+		return e.pace(conn, stream, clonePacket(sent))
+	}
+	if err := e.transmitRTP(conn, sent); err != nil {
+		log.WithError(err).Warn("Could not retransmit RTP packet.")
+		return false
+	}
+	return true
+}
+
+// admit reports whether an RTP packet is within the bandwidth caps of the
+// client and the node, and counts the packets dropped.
+func (e *Endpoint) admit(stream *Stream, packet []byte, now time.Time) bool {
+	if e.limit != nil && !e.limit.allow(len(packet), discardable(stream.codec, packet), now) {
+		stream.stats.add("capped_drops", 1)
+		return false
+	}
+	if nodeEgress != nil && !nodeEgress.allow(len(packet), discardable(stream.codec, packet), now) {
+		stream.stats.add("node_capped_drops", 1)
+		return false
+	}
+	return true
+}
+
+// pace queues a packet, as sent on the wire, to the client's pacer and
+// reports whether it was queued.
+func (e *Endpoint) pace(conn *net.UDPConn, stream *Stream, packet []byte) bool {
+	queued := e.pacer.enqueue(pacedPacket{
+		conn:    e.rtpEgress(conn),
+		address: e.address,
+		oob:     trafficClass(e.address.IP, e.dscp),
+		packet:  packet,
+	})
+	if !queued {
+		stream.stats.add("paced_drops", 1)
+	}
+	return queued
+}
+
+// discardable reports whether an RTP packet carries a video frame that no
+// other frame is predicted from, so that it can be dropped first.
+func discardable(codec pb.VideoCodec, packet []byte) bool {
+	payload, ok := rtpPayload(packet)
+	if !ok || len(payload) == 0 {
+		return false
+	}
+	switch codec {
+	case pb.VideoCodec_H264:
+		// nal_ref_idc is 0, also in the indicator of aggregation and fragmentation units
+		return payload[0]&0x60 == 0
+	case pb.VideoCodec_H265:
+		if len(payload) < 2 {
+			return false
+		}
+		nalType := payload[0] >> 1 & 0x3f
+		if nalType == 49 && len(payload) > 2 {
+			// fragmentation unit
+			nalType = payload[2] & 0x3f
+		}
+		// sub-layer non-reference pictures, TRAIL_N to RSV_VCL_N14
+		return nalType <= 14 && nalType%2 == 0
+	}
+	return false
+}
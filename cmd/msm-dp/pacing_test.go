@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	// 80 kbps is 10000 bytes per second, with a burst of 5000 bytes
+	b := newTokenBucket(80, capBurst, now)
+	require.True(t, b.allow(2000, true, now))
+	require.False(t, b.allow(1000, true, now), "discardable packets leave half the burst")
+	require.True(t, b.allow(3000, false, now))
+	require.False(t, b.allow(1, false, now))
+	require.True(t, b.allow(1000, false, now.Add(100*time.Millisecond)))
+
+	b = newTokenBucket(80, pacingBurst, now)
+	require.Zero(t, b.wait(1500, now))
+	require.Equal(t, 100*time.Millisecond, b.wait(1000, now))
+	require.Equal(t, 100*time.Millisecond, b.wait(1000, now.Add(100*time.Millisecond)))
+}
+
+func TestDiscardable(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   pb.VideoCodec
+		payload []byte
+		want    bool
+	}{
+		{"h264 idr", pb.VideoCodec_H264, []byte{0x65}, false},
+		{"h264 reference p", pb.VideoCodec_H264, []byte{0x41}, false},
+		{"h264 non-reference b", pb.VideoCodec_H264, []byte{0x01}, true},
+		{"h264 non-reference fu-a", pb.VideoCodec_H264, []byte{0x1c, 0x81}, true},
+		{"h265 trail_n", pb.VideoCodec_H265, []byte{0x00, 0x01}, true},
+		{"h265 trail_r", pb.VideoCodec_H265, []byte{0x02, 0x01}, false},
+		{"h265 idr", pb.VideoCodec_H265, []byte{0x26, 0x01}, false},
+		{"h265 fu trail_n", pb.VideoCodec_H265, []byte{0x62, 0x01, 0x80}, true},
+		{"h265 fu trail_r", pb.VideoCodec_H265, []byte{0x62, 0x01, 0x81}, false},
+		{"unknown codec", pb.VideoCodec_CODEC_NONE, []byte{0x01}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, discardable(test.codec, rtpPacket(1, 100, test.payload...)))
+		})
+	}
+}
+
+func TestPacedClient(t *testing.T) {
+	conn := listenLocalUDP(t)
+	client := listenLocalUDP(t)
+
+	stream := &Stream{stats: newCounters(), codec: pb.VideoCodec_H264}
+	// 800 kbps is 100000 bytes per second
+	endpoint := &Endpoint{address: *client.LocalAddr().(*net.UDPAddr), pacer: newPacer(800)}
+	defer endpoint.close()
+
+	payload := make([]byte, 1000-rtpHeaderLen)
+	payload[0] = 0x65
+	start := time.Now()
+	for i := 0; i < 11; i++ {
+		endpoint.sendRTP(conn, stream, rtpPacket(uint16(i), 100, payload...), start)
+	}
+	require.Equal(t, uint32(11), endpoint.packets)
+
+	buffer := make([]byte, 1500)
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	for i := 0; i < 11; i++ {
+		_, err := client.Read(buffer)
+		require.NoError(t, err)
+		require.Equal(t, uint16(i), rtpSequence(buffer))
+	}
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond, "the burst is spread over 100ms")
+}
+
+func TestCappedClient(t *testing.T) {
+	conn := listenLocalUDP(t)
+
+	now := time.Now()
+	stream := &Stream{stats: newCounters(), codec: pb.VideoCodec_H264}
+	// 80 kbps allows a burst of 5000 bytes
+	endpoint := &Endpoint{address: net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7700}, limit: newTokenBucket(80, capBurst, now)}
+
+	payload := make([]byte, 1000-rtpHeaderLen)
+	payload[0] = 0x01
+	for i := 0; i < 3; i++ {
+		endpoint.sendRTP(conn, stream, rtpPacket(uint16(i), 100, payload...), now)
+	}
+	payload[0] = 0x41
+	for i := 3; i < 6; i++ {
+		endpoint.sendRTP(conn, stream, rtpPacket(uint16(i), 200, payload...), now)
+	}
+	require.Equal(t, uint32(5), endpoint.packets, "non-reference packets are dropped first")
+	require.Equal(t, uint64(1), stream.stats.snapshot()["capped_drops"])
+}
+
+func TestCappedRetransmits(t *testing.T) {
+	conn := listenLocalUDP(t)
+
+	nack, err := newNACKCache(16)
+	require.NoError(t, err)
+	stream := &Stream{stats: newCounters(), nack: nack}
+	// 80 kbps allows a burst of 5000 bytes
+	endpoint := &Endpoint{address: net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7702}, limit: newTokenBucket(80, capBurst, time.Now())}
+	payload := make([]byte, 1000-rtpHeaderLen)
+	for seq := uint16(0); seq < 8; seq++ {
+		stream.nack.store(rtpPacket(seq, 100, payload...))
+	}
+
+	retransmit(conn, 27, stream, endpoint, []uint16{0, 1, 2, 3, 4, 5, 6, 7})
+	counters := stream.stats.snapshot()
+	require.Equal(t, uint64(5), counters["retransmits"], "retransmits are capped like forwarded packets")
+	require.Equal(t, uint64(3), counters["capped_drops"])
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+const defaultClockRate = 90000
+
+// rtpRewriter presents a stream with a stable SSRC and continuous sequence
+// numbers and timestamps when the source of the stream changes.
+type rtpRewriter struct {
+	clockRate uint32
+
+	// ssrc is the outbound SSRC, taken from the first source
+	ssrc    uint32
+	started bool
+	// switching is set when the next packet comes from a new source
+	switching bool
+
+	inSSRC    uint32
+	seqOffset uint16
+	tsOffset  uint32
+
+	lastSeq  uint16
+	lastTS   uint32
+	lastTime time.Time
+}
+
+func newRTPRewriter(clockRate uint32) *rtpRewriter {
+	if clockRate == 0 {
+		clockRate = defaultClockRate
+	}
+	return &rtpRewriter{clockRate: clockRate}
+}
+
+// switchSource makes the next packet continue the outbound sequence even if
+// the new source uses the same SSRC as the previous one.
+func (r *rtpRewriter) switchSource() {
+	r.switching = true
+}
+
+// rewrite updates the SSRC, sequence number and timestamp of an RTP packet in place.
+func (r *rtpRewriter) rewrite(packet []byte, now time.Time) {
+	if len(packet) < rtpHeaderLen {
+		return
+	}
+	ssrc, seq, ts := rtpSSRC(packet), rtpSequence(packet), rtpTimestamp(packet)
+
+	switch {
+	case !r.started:
+		r.started = true
+		r.ssrc = ssrc
+		r.inSSRC = ssrc
+		r.lastSeq = seq - 1
+		r.lastTS = ts
+		r.lastTime = now
+	case r.switching || ssrc != r.inSSRC:
+		// continue from the last outbound packet, advancing the timestamp by the elapsed time
+		elapsed := uint32(now.Sub(r.lastTime).Seconds() * float64(r.clockRate))
+		if elapsed == 0 {
+			elapsed = 1
+		}
+		r.seqOffset = r.lastSeq + 1 - seq
+		r.tsOffset = r.lastTS + elapsed - ts
+		r.inSSRC = ssrc
+		r.switching = false
+	}
+
+	outSeq := seq + r.seqOffset
+	outTS := ts + r.tsOffset
+	binary.BigEndian.PutUint16(packet[2:4], outSeq)
+	binary.BigEndian.PutUint32(packet[4:8], outTS)
+	binary.BigEndian.PutUint32(packet[8:12], r.ssrc)
+
+	if int16(outSeq-r.lastSeq) > 0 {
+		r.lastSeq = outSeq
+		r.lastTS = outTS
+		r.lastTime = now
+	}
+}
+
+// rewriteRTCP updates the SSRCs and the SR RTP timestamp of the current
+// source in a compound RTCP packet in place.
+func (r *rtpRewriter) rewriteRTCP(compound []byte) {
+	if !r.started {
+		return
+	}
+	replace := func(field []byte) {
+		if binary.BigEndian.Uint32(field) == r.inSSRC {
+			binary.BigEndian.PutUint32(field, r.ssrc)
+		}
+	}
+
+	forEachRTCP(compound, func(packetType, count byte, packet []byte) {
+		switch packetType {
+		case rtcpSR:
+			if len(packet) < 28 || binary.BigEndian.Uint32(packet[4:8]) != r.inSSRC {
+				return
+			}
+			replace(packet[4:8])
+			binary.BigEndian.PutUint32(packet[16:20], binary.BigEndian.Uint32(packet[16:20])+r.tsOffset)
+		case rtcpSDES:
+			for chunks := packet[4:]; len(chunks) >= 4 && count > 0; count-- {
+				replace(chunks[0:4])
+				// skip the SDES items up to the null terminator and the padding to 32 bits
+				offset := 4
+				for offset < len(chunks) && chunks[offset] != 0 {
+					if offset+1 >= len(chunks) {
+						return
+					}
+					offset += 2 + int(chunks[offset+1])
+				}
+				offset = (offset + 4) &^ 3
+				if offset > len(chunks) {
+					return
+				}
+				chunks = chunks[offset:]
+			}
+		case rtcpBYE:
+			for i := 0; i < int(count) && 8+4*i <= len(packet); i++ {
+				replace(packet[4+4*i : 8+4*i])
+			}
+		}
+	})
+}
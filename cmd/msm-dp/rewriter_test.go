@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func rtpPacketFrom(ssrc uint32, seq uint16, timestamp uint32) []byte {
+	packet := rtpPacket(seq, timestamp)
+	binary.BigEndian.PutUint32(packet[8:], ssrc)
+	return packet
+}
+
+func TestRTPRewriterSourceChange(t *testing.T) {
+	rewriter := newRTPRewriter(90000)
+	now := time.Now()
+
+	first := rtpPacketFrom(0xaaaa, 1000, 50000)
+	rewriter.rewrite(first, now)
+	require.Equal(t, uint32(0xaaaa), rtpSSRC(first), "outbound SSRC is taken from the first source")
+	require.Equal(t, uint16(1000), rtpSequence(first))
+
+	second := rtpPacketFrom(0xaaaa, 1001, 53000)
+	rewriter.rewrite(second, now.Add(33*time.Millisecond))
+
+	switched := rtpPacketFrom(0xbbbb, 7, 1234)
+	rewriter.rewrite(switched, now.Add(133*time.Millisecond))
+	require.Equal(t, uint32(0xaaaa), rtpSSRC(switched))
+	require.Equal(t, uint16(1002), rtpSequence(switched))
+	require.Equal(t, uint32(53000+9000), rtpTimestamp(switched))
+
+	next := rtpPacketFrom(0xbbbb, 8, 4234)
+	rewriter.rewrite(next, now.Add(166*time.Millisecond))
+	require.Equal(t, uint16(1003), rtpSequence(next))
+	require.Equal(t, uint32(53000+9000+3000), rtpTimestamp(next))
+
+	sr := make([]byte, 28)
+	sr[0], sr[1], sr[3] = 0x80, rtcpSR, 6
+	binary.BigEndian.PutUint32(sr[4:], 0xbbbb)
+	binary.BigEndian.PutUint32(sr[16:], 4234)
+	sdes := []byte{0x81, rtcpSDES, 0x00, 0x03, 0x00, 0x00, 0xbb, 0xbb, 0x01, 0x02, 'a', 'b', 0x00, 0x00, 0x00, 0x00}
+	compound := append(sr, sdes...)
+	rewriter.rewriteRTCP(compound)
+	require.Equal(t, uint32(0xaaaa), binary.BigEndian.Uint32(compound[4:]))
+	require.Equal(t, uint32(53000+9000+3000), binary.BigEndian.Uint32(compound[16:]))
+	require.Equal(t, uint32(0xaaaa), binary.BigEndian.Uint32(compound[32:]))
+}
+
+func TestRTPRewriterSameSSRC(t *testing.T) {
+	rewriter := newRTPRewriter(0)
+	now := time.Now()
+
+	rewriter.rewrite(rtpPacketFrom(0xaaaa, 500, 0), now)
+	rewriter.switchSource()
+	packet := rtpPacketFrom(0xaaaa, 20, 0)
+	rewriter.rewrite(packet, now.Add(time.Second))
+	require.Equal(t, uint16(501), rtpSequence(packet))
+	require.Equal(t, uint32(defaultClockRate), rtpTimestamp(packet))
+}
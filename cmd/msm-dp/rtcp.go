@@ -0,0 +1,81 @@
+package main
+
+import "encoding/binary"
+
+const (
+	rtcpSR    = 200
+	rtcpRR    = 201
+	rtcpSDES  = 202
+	rtcpBYE   = 203
+	rtcpAPP   = 204
+	rtcpRTPFB = 205
+	rtcpPSFB  = 206
+)
+
+// forEachRTCP calls fn for each packet in a compound RTCP packet with the
+// packet type, the count/format field and the whole packet including its header.
+// It returns false if the compound packet is malformed.
+func forEachRTCP(compound []byte, fn func(packetType, count byte, packet []byte)) bool {
+	for len(compound) > 0 {
+		if len(compound) < 4 || compound[0]>>6 != 2 {
+			return false
+		}
+		length := 4 * (int(binary.BigEndian.Uint16(compound[2:4])) + 1)
+		if length > len(compound) {
+			return false
+		}
+		fn(compound[1], compound[0]&0x1f, compound[:length])
+		compound = compound[length:]
+	}
+	return true
+}
+
+// genericNACKs returns the sequence numbers reported lost in an RTPFB generic NACK (RFC 4585 §6.2.1).
+func genericNACKs(packet []byte) []uint16 {
+	if len(packet) < 12 {
+		return nil
+	}
+	var lost []uint16
+	for fci := packet[12:]; len(fci) >= 4; fci = fci[4:] {
+		pid := binary.BigEndian.Uint16(fci[0:2])
+		blp := binary.BigEndian.Uint16(fci[2:4])
+		lost = append(lost, pid)
+		for i := uint16(0); i < 16; i++ {
+			if blp&(1<<i) != 0 {
+				lost = append(lost, pid+i+1)
+			}
+		}
+	}
+	return lost
+}
+
+// byeReason returns the optional reason for leaving in an RTCP BYE packet.
+func byeReason(packet []byte, count byte) string {
+	offset := 4 + 4*int(count)
+	if offset >= len(packet) || offset+1+int(packet[offset]) > len(packet) {
+		return ""
+	}
+	return string(packet[offset+1 : offset+1+int(packet[offset])])
+}
+
+// findBYE returns whether a compound RTCP packet contains a BYE packet and its reason.
+func findBYE(compound []byte) (bool, string) {
+	found, reason := false, ""
+	forEachRTCP(compound, func(packetType, count byte, packet []byte) {
+		if packetType == rtcpBYE {
+			found = true
+			reason = byeReason(packet, count)
+		}
+	})
+	return found, reason
+}
+
+// byePacket builds an RTCP BYE packet for a single SSRC.
+func byePacket(ssrc uint32) []byte {
+	bye := make([]byte, 8)
+	bye[0] = 0x81
+	bye[1] = rtcpBYE
+	binary.BigEndian.PutUint16(bye[2:4], 1)
+	binary.BigEndian.PutUint32(bye[4:8], ssrc)
+	return bye
+}
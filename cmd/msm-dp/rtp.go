@@ -0,0 +1,53 @@
+package main
+
+import "encoding/binary"
+
+const rtpHeaderLen = 12
+
+// rtpPayload returns the payload of an RTP packet, skipping the CSRC list,
+// header extension and padding.
+func rtpPayload(packet []byte) ([]byte, bool) {
+	offset, ok := rtpHeaderLength(packet)
+	if !ok {
+		return nil, false
+	}
+	end := len(packet)
+	if packet[0]&0x20 != 0 && end > 0 {
+		end -= int(packet[end-1])
+	}
+	if offset > end {
+		return nil, false
+	}
+	return packet[offset:end], true
+}
+
+// rtpHeaderLength returns the length of an RTP packet's header, with its CSRC
+// list and header extension.
+func rtpHeaderLength(packet []byte) (int, bool) {
+	if len(packet) < rtpHeaderLen || packet[0]>>6 != 2 {
+		return 0, false
+	}
+	offset := rtpHeaderLen + 4*int(packet[0]&0x0f)
+	if packet[0]&0x10 != 0 {
+		if len(packet) < offset+4 {
+			return 0, false
+		}
+		offset += 4 + 4*int(binary.BigEndian.Uint16(packet[offset+2:]))
+	}
+	if offset > len(packet) {
+		return 0, false
+	}
+	return offset, true
+}
+
+func rtpTimestamp(packet []byte) uint32 {
+	return binary.BigEndian.Uint32(packet[4:8])
+}
+
+func rtpSequence(packet []byte) uint16 {
+	return binary.BigEndian.Uint16(packet[2:4])
+}
+
+func rtpSSRC(packet []byte) uint32 {
+	return binary.BigEndian.Uint32(packet[8:12])
+}
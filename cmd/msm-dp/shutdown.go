@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// gracefulStopTimeout is how long the control plane's calls are waited for
+// before they are cancelled.
+const gracefulStopTimeout = 10 * time.Second
+
+// shutdown stops accepting control changes and reports the data plane as not
+// ready, keeps forwarding for the drain period, then stops the gRPC server.
+func (s *server) shutdown(grpcServer *grpc.Server, health *HealthChecker) {
+	log.Info("Shutting down, control changes are rejected")
+	s.draining.Store(true)
+	health.setReady(componentControlServer, false)
+	health.stop()
+	if *drainPeriod > 0 {
+		log.Infof("Draining streams for %v", *drainPeriod)
+		time.Sleep(*drainPeriod)
+	}
+	s.stopControl(grpcServer)
+}
+
+// stopControl ends the event subscriptions, then stops the gRPC server once
+// the control plane's calls are done.
+func (s *server) stopControl(grpcServer *grpc.Server) {
+	// event subscriptions would keep GracefulStop waiting
+	close(s.stopping)
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(gracefulStopTimeout):
+		log.Warn("Control plane calls still running, cancelling them")
+		grpcServer.Stop()
+		<-stopped
+	}
+}
+
+// closeDataPlane deletes the streams, which leaves their multicast groups
+// and stops their pacers, and closes the shared sockets.
+func closeDataPlane(conns ...*net.UDPConn) {
+	streamsLock.Lock()
+	for streamID := range streams {
+		deleteStream(streamID)
+	}
+	streamsLock.Unlock()
+	for _, conn := range conns {
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			log.WithError(err).Warn("Unable to close socket")
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestShutdown(t *testing.T) {
+	*drainPeriod = 200 * time.Millisecond
+	defer func() { *drainPeriod = 0 }()
+
+	health := NewHealthChecker()
+	for _, component := range readinessComponents {
+		health.setReady(component, true)
+	}
+	dataPlane := &server{stopping: make(chan struct{})}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	pb.RegisterMsmDataPlaneServer(s, dataPlane)
+	served := make(chan error)
+	go func() { served <- s.Serve(lis) }()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewMsmDataPlaneClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := client.StreamEvents(ctx, &pb.StreamEventsRequest{})
+	require.NoError(t, err)
+	_, err = client.StreamAddDel(ctx, &pb.StreamData{Id: 17, Operation: pb.StreamOperation_DELETE})
+	require.NoError(t, err)
+
+	stopped := make(chan struct{})
+	start := time.Now()
+	go func() {
+		dataPlane.shutdown(s, health)
+		close(stopped)
+	}()
+	require.Eventually(t, dataPlane.draining.Load, time.Second, time.Millisecond)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, health.status(readinessService))
+	_, err = client.StreamAddDel(ctx, &pb.StreamData{Id: 17, Operation: pb.StreamOperation_DELETE})
+	require.Equal(t, codes.Unavailable, status.Code(err), "control changes are rejected while draining")
+
+	_, err = events.Recv()
+	require.Equal(t, codes.Unavailable, status.Code(err), "event subscriptions end")
+	<-stopped
+	require.GreaterOrEqual(t, time.Since(start), *drainPeriod)
+	require.NoError(t, <-served)
+}
+
+func TestCloseDataPlane(t *testing.T) {
+	conn := listenLocalUDP(t)
+	forwarded := make(chan struct{})
+	go func() {
+		forwardRTPPackets(conn)
+		close(forwarded)
+	}()
+
+	s := &server{}
+	_, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 17, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8000},
+	})
+	require.NoError(t, err)
+	_, err = s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 17, Operation: pb.StreamOperation_ADD_EP, Enable: true, PacingKbps: 1000,
+		Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8002},
+	})
+	require.NoError(t, err)
+	streamsLock.RLock()
+	pacer := streams[17].clients["127.0.0.1:8002"].pacer
+	streamsLock.RUnlock()
+
+	closeDataPlane(conn)
+	select {
+	case <-forwarded:
+	case <-time.After(time.Second):
+		t.Fatal("forwarding didn't stop when its socket was closed")
+	}
+	streamsLock.RLock()
+	require.NotContains(t, streams, uint32(17))
+	streamsLock.RUnlock()
+	select {
+	case <-pacer.done:
+	default:
+		t.Fatal("pacers are stopped")
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// mergeWindow is the number of sequence numbers remembered to de-duplicate
+// packets received from redundant sources.
+const mergeWindow = 1024
+
+// source is an address a stream receives RTP packets from.
+type source struct {
+	address net.UDPAddr
+	// stats tracks the packets received from this source, for per source loss
+	stats      receiverStats
+	lastPacket time.Time
+	// bye is set when the source has sent an RTCP BYE
+	bye bool
+	// srtp is set when the source sends SRTP
+	srtp *srtpContext
+}
+
+func newSource(ip string, port uint32) *source {
+	return &source{address: parseAddress(ip, port)}
+}
+
+// findSource returns the stream's source with the given address, or with the
+// given address minus one for RTCP packets.
+func (s *Stream) findSource(address *net.UDPAddr, rtcp bool) *source {
+	rtpAddress := *address
+	if rtcp {
+		rtpAddress.Port--
+	}
+	for _, src := range s.sources {
+		if sameAddress(src.address, rtpAddress) {
+			return src
+		}
+	}
+	return nil
+}
+
+// sourceCounters returns the reception counters of each source of the stream.
+func (s *Stream) sourceCounters() map[string]uint64 {
+	values := make(map[string]uint64)
+	for i, src := range s.sources {
+		values[fmt.Sprintf("source_%d_received", i)] = uint64(src.stats.received)
+		lost := src.stats.lost()
+		if lost < 0 {
+			lost = 0
+		}
+		values[fmt.Sprintf("source_%d_lost", i)] = uint64(lost)
+	}
+	return values
+}
+
+// seqMerger merges the packets of redundant sources into one stream, in the
+// style of SMPTE 2022-7: the first copy of each sequence number is forwarded
+// and later copies are dropped.
+type seqMerger struct {
+	started bool
+	highest uint16
+	seen    [mergeWindow / 64]uint64
+}
+
+func (m *seqMerger) mark(seq uint16, seen bool) {
+	slot := seq % mergeWindow
+	if seen {
+		m.seen[slot/64] |= 1 << (slot % 64)
+	} else {
+		m.seen[slot/64] &^= 1 << (slot % 64)
+	}
+}
+
+func (m *seqMerger) marked(seq uint16) bool {
+	slot := seq % mergeWindow
+	return m.seen[slot/64]&(1<<(slot%64)) != 0
+}
+
+// accept reports whether a packet with this sequence number should be
+// forwarded, i.e. it hasn't been forwarded yet.
+func (m *seqMerger) accept(seq uint16) bool {
+	delta := int16(seq - m.highest)
+	switch {
+	case !m.started || delta < -maxDropout:
+		// first packet or the sources restarted
+		*m = seqMerger{started: true, highest: seq}
+	case delta > 0:
+		if delta >= mergeWindow {
+			m.seen = [mergeWindow / 64]uint64{}
+		} else {
+			for s := m.highest + 1; s != seq; s++ {
+				m.mark(s, false)
+			}
+		}
+		m.highest = seq
+	case -delta >= mergeWindow || m.marked(seq):
+		return false
+	}
+	m.mark(seq, true)
+	return true
+}
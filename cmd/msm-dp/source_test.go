@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestSeqMerger(t *testing.T) {
+	var merger seqMerger
+	require.True(t, merger.accept(65534))
+	require.False(t, merger.accept(65534), "duplicate")
+	require.True(t, merger.accept(1), "gap across the wrap")
+	require.True(t, merger.accept(65535), "late packet filling the gap")
+	require.True(t, merger.accept(0))
+	require.False(t, merger.accept(0))
+	require.False(t, merger.accept(1))
+
+	require.True(t, merger.accept(1+mergeWindow))
+	require.False(t, merger.accept(1), "too old")
+	require.True(t, merger.accept(40000), "sources restarted")
+}
+
+func TestForwardRedundantSources(t *testing.T) {
+	conn := listenLocalUDP(t)
+	client := listenLocalUDP(t)
+
+	legA := newSource("127.0.0.1", 6000)
+	legB := newSource("127.0.0.1", 6002)
+	clientAddr := client.LocalAddr().(*net.UDPAddr)
+	stream := &Stream{
+		sources: []*source{legA, legB},
+		merger:  &seqMerger{},
+		clients: map[string]*Endpoint{clientAddr.String(): {enabled: true, address: *clientAddr}},
+		stats:   newCounters(),
+	}
+
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	streams[3] = stream
+	streamMap[addressKey(legA.address)] = 3
+	streamMap[addressKey(legB.address)] = 3
+	defer func() {
+		delete(streams, 3)
+		delete(streamMap, addressKey(legA.address))
+		delete(streamMap, addressKey(legB.address))
+	}()
+
+	// leg A loses 2, leg B loses 3 and 4
+	for seq := uint16(1); seq <= 5; seq++ {
+		if seq != 2 {
+			forwardRTPPacket(conn, rtpPacket(seq, 0), &legA.address)
+		}
+		if seq != 3 && seq != 4 {
+			forwardRTPPacket(conn, rtpPacket(seq, 0), &legB.address)
+		}
+	}
+	// too short to merge by sequence number
+	forwardRTPPacket(conn, []byte{0x80, 96}, &legB.address)
+
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	buffer := make([]byte, 1500)
+	for _, seq := range []uint16{1, 2, 3, 4, 5} {
+		n, err := client.Read(buffer)
+		require.NoError(t, err)
+		require.Equal(t, seq, rtpSequence(buffer[:n]))
+	}
+
+	counters := stream.sourceCounters()
+	require.Equal(t, uint64(1), counters["source_0_lost"])
+	require.Equal(t, uint64(2), counters["source_1_lost"])
+	require.Equal(t, uint64(2), stream.stats.snapshot()["duplicates"])
+	require.Equal(t, uint64(1), stream.stats.snapshot()["malformed_packets"])
+}
+
+func TestUpdateSource(t *testing.T) {
+	s := &server{}
+	ctx := context.Background()
+	for _, operation := range []pb.StreamOperation{pb.StreamOperation_CREATE, pb.StreamOperation_UPDATE, pb.StreamOperation_ADD_EP} {
+		result, err := s.StreamAddDel(ctx, &pb.StreamData{Id: 22, Operation: operation})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.ErrorMessage, "%v without endpoint", operation)
+		require.False(t, result.Success)
+	}
+
+	defer s.StreamAddDel(ctx, &pb.StreamData{Id: 22, Operation: pb.StreamOperation_DELETE})
+	defer s.StreamAddDel(ctx, &pb.StreamData{Id: 23, Operation: pb.StreamOperation_DELETE})
+	result, err := s.StreamAddDel(ctx, &pb.StreamData{Id: 22, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 6100}})
+	require.NoError(t, err)
+	require.Empty(t, result.ErrorMessage)
+	result, err = s.StreamAddDel(ctx, &pb.StreamData{
+		Id: 23, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 6102},
+		RedundantSources: []*pb.Endpoint{{Ip: "127.0.0.1", Port: 6104}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.ErrorMessage)
+
+	result, err = s.StreamAddDel(ctx, &pb.StreamData{Id: 22, Operation: pb.StreamOperation_UPDATE, Endpoint: &pb.Endpoint{Ip: "239.1.2.3", Port: 6100}})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.ErrorMessage, "multicast sources are joined on CREATE")
+	require.False(t, result.Success)
+	result, err = s.StreamAddDel(ctx, &pb.StreamData{Id: 23, Operation: pb.StreamOperation_UPDATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 6106}})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.ErrorMessage, "redundant sources are merged as created")
+
+	result, err = s.StreamAddDel(ctx, &pb.StreamData{Id: 22, Operation: pb.StreamOperation_UPDATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 6108}})
+	require.NoError(t, err)
+	require.Empty(t, result.ErrorMessage)
+	require.True(t, result.Success)
+	result, err = s.StreamAddDel(ctx, &pb.StreamData{Id: 22, Operation: pb.StreamOperation_DEL_EP, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 6110}})
+	require.NoError(t, err)
+	require.False(t, result.Success, "unknown clients aren't deleted")
+	streamsLock.RLock()
+	defer streamsLock.RUnlock()
+	require.Equal(t, uint32(22), streamMap[addressKey(parseAddress("127.0.0.1", 6108))])
+	_, ok := streamMap[addressKey(parseAddress("127.0.0.1", 6100))]
+	require.False(t, ok)
+}
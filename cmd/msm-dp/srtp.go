@@ -0,0 +1,363 @@
+package main
+
+import (
+	"cmp"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+const (
+	srtpKeyLen  = 16
+	srtpSaltLen = 14
+	srtpAuthLen = 20
+	// srtcpTagLen is the tag length of SRTCP packets for both suites
+	srtcpTagLen = 10
+	// srtcpIndexLen is the E flag and SRTCP index of SRTCP packets
+	srtcpIndexLen = 4
+	// replayWindowSize is the number of packets remembered to reject replays
+	replayWindowSize = 64
+)
+
+// SRTP key derivation labels, RFC 3711 section 4.3.2
+const (
+	labelRTPEncryption  = 0x00
+	labelRTPAuth        = 0x01
+	labelRTPSalt        = 0x02
+	labelRTCPEncryption = 0x03
+	labelRTCPAuth       = 0x04
+	labelRTCPSalt       = 0x05
+)
+
+var (
+	errSRTPAuth   = errors.New("SRTP authentication failed")
+	errSRTPReplay = errors.New("SRTP packet replayed")
+)
+
+// srtpTagLens are the SRTP tag lengths of the supported crypto suites.
+var srtpTagLens = map[string]int{
+	"AES_CM_128_HMAC_SHA1_80": 10,
+	"AES_CM_128_HMAC_SHA1_32": 4,
+}
+
+// srtpKeys are the session keys of SRTP or SRTCP.
+type srtpKeys struct {
+	block cipher.Block
+	salt  []byte
+	auth  hash.Hash
+}
+
+// srtpState is the state of one SSRC in one direction.
+type srtpState struct {
+	started bool
+	// roc and seq are the rollover counter and highest sequence number
+	roc    uint32
+	seq    uint16
+	replay replayWindow
+	// rtcpIndex is the SRTCP index of the last packet sent
+	rtcpIndex  uint32
+	rtcpReplay replayWindow
+}
+
+// srtpContext protects the packets sent to an endpoint and unprotects the
+// packets received from it, with the master key of each direction.
+type srtpContext struct {
+	tagLen   int
+	outbound srtpSession
+	inbound  srtpSession
+	sent     map[uint32]*srtpState
+	received map[uint32]*srtpState
+}
+
+// srtpSession holds the session keys derived from a master key.
+type srtpSession struct {
+	rtp  srtpKeys
+	rtcp srtpKeys
+}
+
+// newSRTPContext derives the session keys from the SDES crypto attributes.
+func newSRTPContext(crypto *pb.SrtpCrypto) (*srtpContext, error) {
+	tagLen, ok := srtpTagLens[crypto.Suite]
+	if !ok {
+		return nil, fmt.Errorf("unsupported SRTP crypto suite %q", crypto.Suite)
+	}
+	c := &srtpContext{tagLen: tagLen, sent: make(map[uint32]*srtpState), received: make(map[uint32]*srtpState)}
+	var err error
+	if c.outbound, err = newSRTPSession(cmp.Or(crypto.OutboundKeyParams, crypto.KeyParams)); err != nil {
+		return nil, fmt.Errorf("outbound %w", err)
+	}
+	if c.inbound, err = newSRTPSession(cmp.Or(crypto.InboundKeyParams, crypto.KeyParams)); err != nil {
+		return nil, fmt.Errorf("inbound %w", err)
+	}
+	return c, nil
+}
+
+// newSRTPSession derives the session keys from SDES key params.
+func newSRTPSession(keyParams string) (srtpSession, error) {
+	params, ok := strings.CutPrefix(keyParams, "inline:")
+	if !ok {
+		return srtpSession{}, fmt.Errorf("SRTP key params must be inline")
+	}
+	// the key may be followed by a lifetime and MKI, which aren't used
+	params, _, _ = strings.Cut(params, "|")
+	keySalt, err := base64.StdEncoding.DecodeString(params)
+	if err != nil {
+		return srtpSession{}, fmt.Errorf("SRTP key is invalid: %w", err)
+	}
+	if len(keySalt) != srtpKeyLen+srtpSaltLen {
+		return srtpSession{}, fmt.Errorf("SRTP key and salt must be %d bytes", srtpKeyLen+srtpSaltLen)
+	}
+	master, err := aes.NewCipher(keySalt[:srtpKeyLen])
+	if err != nil {
+		return srtpSession{}, err
+	}
+	masterSalt := keySalt[srtpKeyLen:]
+	var session srtpSession
+	if session.rtp, err = deriveSRTPKeys(master, masterSalt, labelRTPEncryption, labelRTPAuth, labelRTPSalt); err != nil {
+		return srtpSession{}, err
+	}
+	if session.rtcp, err = deriveSRTPKeys(master, masterSalt, labelRTCPEncryption, labelRTCPAuth, labelRTCPSalt); err != nil {
+		return srtpSession{}, err
+	}
+	return session, nil
+}
+
+func deriveSRTPKeys(master cipher.Block, masterSalt []byte, encryption, auth, salt byte) (srtpKeys, error) {
+	block, err := aes.NewCipher(deriveSRTPKey(master, masterSalt, encryption, srtpKeyLen))
+	if err != nil {
+		return srtpKeys{}, err
+	}
+	return srtpKeys{
+		block: block,
+		salt:  deriveSRTPKey(master, masterSalt, salt, srtpSaltLen),
+		auth:  hmac.New(sha1.New, deriveSRTPKey(master, masterSalt, auth, srtpAuthLen)),
+	}, nil
+}
+
+// deriveSRTPKey is the AES-CM key derivation of RFC 3711 section 4.3.3, with
+// a key derivation rate of 0.
+func deriveSRTPKey(master cipher.Block, masterSalt []byte, label byte, n int) []byte {
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, masterSalt)
+	iv[7] ^= label
+	key := make([]byte, n)
+	cipher.NewCTR(master, iv).XORKeyStream(key, key)
+	return key
+}
+
+// xorKeyStream applies the AES-CM keystream of a packet, RFC 3711 section 4.1.1.
+func (k *srtpKeys) xorKeyStream(data []byte, ssrc uint32, index uint64) {
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, k.salt)
+	var ssrcBytes [4]byte
+	binary.BigEndian.PutUint32(ssrcBytes[:], ssrc)
+	subtle.XORBytes(iv[4:8], iv[4:8], ssrcBytes[:])
+	var indexBytes [8]byte
+	binary.BigEndian.PutUint64(indexBytes[:], index<<16)
+	subtle.XORBytes(iv[8:16], iv[8:16], indexBytes[:])
+	cipher.NewCTR(k.block, iv).XORKeyStream(data, data)
+}
+
+// tag returns the HMAC-SHA1 of the packet, followed by the rollover counter for SRTP.
+func (k *srtpKeys) tag(packet []byte, roc []byte, n int) []byte {
+	k.auth.Reset()
+	k.auth.Write(packet)
+	k.auth.Write(roc)
+	return k.auth.Sum(nil)[:n]
+}
+
+func state(states map[uint32]*srtpState, ssrc uint32) *srtpState {
+	s, ok := states[ssrc]
+	if !ok {
+		s = &srtpState{}
+		states[ssrc] = s
+	}
+	return s
+}
+
+// estimateROC guesses the rollover counter of a sequence number, RFC 3711 appendix A.
+func (s *srtpState) estimateROC(seq uint16) uint32 {
+	if !s.started {
+		return 0
+	}
+	if s.seq < 0x8000 {
+		if int(seq)-int(s.seq) > 0x8000 && s.roc > 0 {
+			return s.roc - 1
+		}
+	} else if int(s.seq)-0x8000 > int(seq) {
+		return s.roc + 1
+	}
+	return s.roc
+}
+
+// update remembers the highest index of the SSRC.
+func (s *srtpState) update(roc uint32, seq uint16) {
+	if !s.started || uint64(roc)<<16|uint64(seq) > uint64(s.roc)<<16|uint64(s.seq) {
+		s.started = true
+		s.roc = roc
+		s.seq = seq
+	}
+}
+
+// protectRTP returns the SRTP packet of an RTP packet.
+func (c *srtpContext) protectRTP(packet []byte) ([]byte, error) {
+	headerLen, ok := rtpHeaderLength(packet)
+	if !ok {
+		return nil, fmt.Errorf("invalid RTP packet")
+	}
+	ssrc, seq := rtpSSRC(packet), rtpSequence(packet)
+	s := state(c.sent, ssrc)
+	roc := s.estimateROC(seq)
+	s.update(roc, seq)
+
+	out := make([]byte, len(packet), len(packet)+c.tagLen)
+	copy(out, packet)
+	c.outbound.rtp.xorKeyStream(out[headerLen:], ssrc, uint64(roc)<<16|uint64(seq))
+	return append(out, c.outbound.rtp.tag(out, binary.BigEndian.AppendUint32(nil, roc), c.tagLen)...), nil
+}
+
+// unprotectRTP authenticates an SRTP packet and returns its RTP packet.
+func (c *srtpContext) unprotectRTP(packet []byte) ([]byte, error) {
+	if len(packet) < rtpHeaderLen+c.tagLen {
+		return nil, fmt.Errorf("invalid SRTP packet")
+	}
+	authenticated := packet[:len(packet)-c.tagLen]
+	headerLen, ok := rtpHeaderLength(authenticated)
+	if !ok {
+		return nil, fmt.Errorf("invalid SRTP packet")
+	}
+	ssrc, seq := rtpSSRC(packet), rtpSequence(packet)
+	// stored once authenticated, so that forged SSRCs aren't remembered
+	s, known := c.received[ssrc]
+	if !known {
+		s = &srtpState{}
+	}
+	roc := s.estimateROC(seq)
+	index := uint64(roc)<<16 | uint64(seq)
+	if s.replay.seen(index) {
+		return nil, errSRTPReplay
+	}
+	tag := c.inbound.rtp.tag(authenticated, binary.BigEndian.AppendUint32(nil, roc), c.tagLen)
+	if !hmac.Equal(tag, packet[len(authenticated):]) {
+		return nil, errSRTPAuth
+	}
+	if !known {
+		c.received[ssrc] = s
+	}
+	s.replay.add(index)
+	s.update(roc, seq)
+
+	out := append([]byte(nil), authenticated...)
+	c.inbound.rtp.xorKeyStream(out[headerLen:], ssrc, index)
+	return out, nil
+}
+
+// protectRTCP returns the SRTCP packet of a compound RTCP packet, encrypted
+// with the next SRTCP index of its sender.
+func (c *srtpContext) protectRTCP(packet []byte) ([]byte, error) {
+	if len(packet) < 8 {
+		return nil, fmt.Errorf("invalid RTCP packet")
+	}
+	ssrc := binary.BigEndian.Uint32(packet[4:8])
+	s := state(c.sent, ssrc)
+	s.rtcpIndex = (s.rtcpIndex + 1) & 0x7fffffff
+
+	out := make([]byte, len(packet), len(packet)+srtcpIndexLen+srtcpTagLen)
+	copy(out, packet)
+	c.outbound.rtcp.xorKeyStream(out[8:], ssrc, uint64(s.rtcpIndex))
+	out = binary.BigEndian.AppendUint32(out, 0x80000000|s.rtcpIndex)
+	return append(out, c.outbound.rtcp.tag(out, nil, srtcpTagLen)...), nil
+}
+
+// unprotectRTCP authenticates an SRTCP packet and returns its compound RTCP packet.
+func (c *srtpContext) unprotectRTCP(packet []byte) ([]byte, error) {
+	if len(packet) < 8+srtcpIndexLen+srtcpTagLen {
+		return nil, fmt.Errorf("invalid SRTCP packet")
+	}
+	authenticated := packet[:len(packet)-srtcpTagLen]
+	ssrc := binary.BigEndian.Uint32(packet[4:8])
+	eIndex := binary.BigEndian.Uint32(authenticated[len(authenticated)-srtcpIndexLen:])
+	index := uint64(eIndex & 0x7fffffff)
+	s, known := c.received[ssrc]
+	if !known {
+		s = &srtpState{}
+	}
+	if s.rtcpReplay.seen(index) {
+		return nil, errSRTPReplay
+	}
+	if !hmac.Equal(c.inbound.rtcp.tag(authenticated, nil, srtcpTagLen), packet[len(authenticated):]) {
+		return nil, errSRTPAuth
+	}
+	if !known {
+		c.received[ssrc] = s
+	}
+	s.rtcpReplay.add(index)
+
+	out := append([]byte(nil), authenticated[:len(authenticated)-srtcpIndexLen]...)
+	if eIndex&0x80000000 != 0 {
+		c.inbound.rtcp.xorKeyStream(out[8:], ssrc, index)
+	}
+	return out, nil
+}
+
+// srtpCounter returns the stream counter of an SRTP unprotect error.
+func srtpCounter(err error) string {
+	if errors.Is(err, errSRTPReplay) {
+		return "srtp_replays"
+	}
+	return "srtp_auth_failures"
+}
+
+// replayWindow remembers the indexes received below the highest one, RFC 3711
+// section 3.3.2.
+type replayWindow struct {
+	started bool
+	highest uint64
+	bitmap  uint64
+}
+
+// seen reports whether the index was received or is too old to tell.
+func (w *replayWindow) seen(index uint64) bool {
+	if !w.started || index > w.highest {
+		return false
+	}
+	diff := w.highest - index
+	return diff >= replayWindowSize || w.bitmap&(1<<diff) != 0
+}
+
+func (w *replayWindow) add(index uint64) {
+	switch {
+	case !w.started:
+		w.started = true
+		w.highest = index
+		w.bitmap = 1
+	case index > w.highest:
+		if shift := index - w.highest; shift < replayWindowSize {
+			w.bitmap = w.bitmap<<shift | 1
+		} else {
+			w.bitmap = 1
+		}
+		w.highest = index
+	default:
+		w.bitmap |= 1 << (w.highest - index)
+	}
+}
+
+// endpointSRTP returns the SRTP context of an endpoint, or nil if it
+// exchanges clear RTP.
+func endpointSRTP(endpoint *pb.Endpoint) (*srtpContext, error) {
+	if endpoint.GetSrtp() == nil {
+		return nil, nil
+	}
+	return newSRTPContext(endpoint.Srtp)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// RFC 3711 appendix B.2
+func TestSRTPKeyStream(t *testing.T) {
+	block, err := aes.NewCipher(mustHex(t, "2B7E151628AED2A6ABF7158809CF4F3C"))
+	require.NoError(t, err)
+	keys := srtpKeys{block: block, salt: mustHex(t, "F0F1F2F3F4F5F6F7F8F9FAFBFCFD")}
+	keyStream := make([]byte, 0xff02*aes.BlockSize)
+	keys.xorKeyStream(keyStream, 0, 0)
+
+	require.Equal(t, mustHex(t, "E03EAD0935C95E80E166B16DD92B4EB4"), keyStream[0:16])
+	require.Equal(t, mustHex(t, "D23513162B02D0F72A43A2FE4A5F97AB"), keyStream[16:32])
+	require.Equal(t, mustHex(t, "41E95B3BB0A2E8DD477901E4FCA894C0"), keyStream[32:48])
+	require.Equal(t, mustHex(t, "EC8CDF7398607CB0F2D21675EA9EA1E4"), keyStream[0xfeff*16:0xff00*16])
+	require.Equal(t, mustHex(t, "362B7C3C6773516318A077D7FC5073AE"), keyStream[0xff00*16:0xff01*16])
+	require.Equal(t, mustHex(t, "6A2CC3787889374FBEB4C81B17BA6C44"), keyStream[0xff01*16:0xff02*16])
+}
+
+// RFC 3711 appendix B.3
+func TestSRTPKeyDerivation(t *testing.T) {
+	master, err := aes.NewCipher(mustHex(t, "E1F97A0D3E018BE0D64FA32C06DE4139"))
+	require.NoError(t, err)
+	salt := mustHex(t, "0EC675AD498AFEEBB6960B3AABE6")
+
+	require.Equal(t, mustHex(t, "C61E7A93744F39EE10734AFE3FF7A087"), deriveSRTPKey(master, salt, labelRTPEncryption, srtpKeyLen))
+	require.Equal(t, mustHex(t, "30CBBC08863D8C85D49DB34A9AE1"), deriveSRTPKey(master, salt, labelRTPSalt, srtpSaltLen))
+	require.Equal(t, mustHex(t, "CEBE321F6FF7716B6FD4AB49AF256A156D38BAA4"), deriveSRTPKey(master, salt, labelRTPAuth, srtpAuthLen))
+}
+
+func testSRTPContext(t *testing.T, seed byte, suite string) (*srtpContext, *pb.SrtpCrypto) {
+	keySalt := make([]byte, srtpKeyLen+srtpSaltLen)
+	for i := range keySalt {
+		keySalt[i] = seed + byte(i)
+	}
+	crypto := &pb.SrtpCrypto{Suite: suite, KeyParams: "inline:" + base64.StdEncoding.EncodeToString(keySalt) + "|2^31"}
+	c, err := newSRTPContext(crypto)
+	require.NoError(t, err)
+	return c, crypto
+}
+
+func TestSRTPContext(t *testing.T) {
+	_, err := newSRTPContext(&pb.SrtpCrypto{Suite: "AES_256_CM_HMAC_SHA1_80", KeyParams: "inline:AAAA"})
+	require.Error(t, err)
+	_, err = newSRTPContext(&pb.SrtpCrypto{Suite: "AES_CM_128_HMAC_SHA1_80", KeyParams: "inline:AAAA"})
+	require.Error(t, err)
+
+	for suite, tagLen := range srtpTagLens {
+		sender, _ := testSRTPContext(t, 1, suite)
+		receiver, _ := testSRTPContext(t, 1, suite)
+		packet := rtpPacket(1, 100, 0x65, 1, 2, 3)
+		protected, err := sender.protectRTP(packet)
+		require.NoError(t, err)
+		require.Len(t, protected, len(packet)+tagLen)
+		require.Equal(t, packet[:rtpHeaderLen], protected[:rtpHeaderLen], "the header is sent in clear")
+		require.NotEqual(t, packet[rtpHeaderLen:], protected[rtpHeaderLen:len(packet)])
+
+		unprotected, err := receiver.unprotectRTP(protected)
+		require.NoError(t, err)
+		require.Equal(t, packet, unprotected)
+		_, err = receiver.unprotectRTP(protected)
+		require.ErrorIs(t, err, errSRTPReplay)
+
+		protected, err = sender.protectRTP(rtpPacket(2, 100, 0x65, 1, 2, 3))
+		require.NoError(t, err)
+		protected[rtpHeaderLen] ^= 1
+		_, err = receiver.unprotectRTP(protected)
+		require.ErrorIs(t, err, errSRTPAuth)
+		binary.BigEndian.PutUint32(protected[8:12], 0xdeadbeef)
+		_, err = receiver.unprotectRTP(protected)
+		require.ErrorIs(t, err, errSRTPAuth)
+		require.Len(t, receiver.received, 1, "SSRCs of unauthenticated packets aren't remembered")
+	}
+}
+
+func TestSRTPRollover(t *testing.T) {
+	sender, _ := testSRTPContext(t, 1, "AES_CM_128_HMAC_SHA1_80")
+	receiver, _ := testSRTPContext(t, 1, "AES_CM_128_HMAC_SHA1_80")
+	for _, seq := range []uint16{65534, 65535, 0, 65533, 1} {
+		packet := rtpPacket(seq, 100, 0x41, 7)
+		protected, err := sender.protectRTP(packet)
+		require.NoError(t, err)
+		unprotected, err := receiver.unprotectRTP(protected)
+		require.NoError(t, err, "sequence number %d", seq)
+		require.Equal(t, packet, unprotected)
+	}
+	require.Equal(t, uint32(1), receiver.received[0x12345678].roc)
+}
+
+func TestSRTCP(t *testing.T) {
+	sender, _ := testSRTPContext(t, 1, "AES_CM_128_HMAC_SHA1_32")
+	receiver, _ := testSRTPContext(t, 1, "AES_CM_128_HMAC_SHA1_32")
+	packet := rtcpBYEPacket(0x12345678, "bye")
+	protected, err := sender.protectRTCP(packet)
+	require.NoError(t, err)
+	require.Len(t, protected, len(packet)+srtcpIndexLen+srtcpTagLen)
+
+	unprotected, err := receiver.unprotectRTCP(protected)
+	require.NoError(t, err)
+	require.Equal(t, packet, unprotected)
+	_, err = receiver.unprotectRTCP(protected)
+	require.ErrorIs(t, err, errSRTPReplay)
+
+	protected, err = sender.protectRTCP(packet)
+	require.NoError(t, err)
+	protected[len(protected)-1] ^= 1
+	_, err = receiver.unprotectRTCP(protected)
+	require.ErrorIs(t, err, errSRTPAuth)
+	binary.BigEndian.PutUint32(protected[4:8], 0xdeadbeef)
+	_, err = receiver.unprotectRTCP(protected)
+	require.ErrorIs(t, err, errSRTPAuth)
+	require.Len(t, receiver.received, 1, "SSRCs of unauthenticated packets aren't remembered")
+}
+
+func TestSRTPStream(t *testing.T) {
+	conn := listenLocalUDP(t)
+	client := listenLocalUDP(t)
+	clientAddr := client.LocalAddr().(*net.UDPAddr)
+
+	source, sourceCrypto := testSRTPContext(t, 1, "AES_CM_128_HMAC_SHA1_80")
+	clientSRTP, clientCrypto := testSRTPContext(t, 100, "AES_CM_128_HMAC_SHA1_80")
+
+	s := &server{}
+	result, err := s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 15, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7800, Srtp: sourceCrypto},
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.ErrorMessage)
+	defer s.StreamAddDel(context.Background(), &pb.StreamData{Id: 15, Operation: pb.StreamOperation_DELETE})
+	result, err = s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 15, Operation: pb.StreamOperation_ADD_EP, Enable: true,
+		Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: uint32(clientAddr.Port), Srtp: clientCrypto},
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.ErrorMessage)
+
+	packet := rtpPacket(1, 100, 0x65, 1, 2, 3)
+	protected, err := source.protectRTP(packet)
+	require.NoError(t, err)
+	streamsLock.Lock()
+	stream := streams[15]
+	forwardStreamRTP(conn, 15, stream, stream.sources[0], protected)
+	forwardStreamRTP(conn, 15, stream, stream.sources[0], protected)
+	streamsLock.Unlock()
+	require.Equal(t, uint64(1), stream.stats.snapshot()["srtp_replays"])
+
+	buffer := make([]byte, 1500)
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := client.Read(buffer)
+	require.NoError(t, err)
+	received, err := clientSRTP.unprotectRTP(buffer[:n])
+	require.NoError(t, err, "the packet is encrypted with the client's key")
+	require.Equal(t, packet, received)
+
+	result, err = s.StreamAddDel(context.Background(), &pb.StreamData{
+		Id: 15, Operation: pb.StreamOperation_ADD_EP,
+		Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 7802, Srtp: &pb.SrtpCrypto{Suite: "NULL"}},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.ErrorMessage)
+}
+
+func TestSRTPDirectionKeys(t *testing.T) {
+	inbound := "inline:" + base64.StdEncoding.EncodeToString(make([]byte, srtpKeyLen+srtpSaltLen))
+	_, outboundCrypto := testSRTPContext(t, 50, "AES_CM_128_HMAC_SHA1_80")
+	outbound := outboundCrypto.KeyParams
+
+	endpoint, err := newSRTPContext(&pb.SrtpCrypto{Suite: "AES_CM_128_HMAC_SHA1_80", InboundKeyParams: inbound, OutboundKeyParams: outbound})
+	require.NoError(t, err)
+	peer, err := newSRTPContext(&pb.SrtpCrypto{Suite: "AES_CM_128_HMAC_SHA1_80", InboundKeyParams: outbound, OutboundKeyParams: inbound})
+	require.NoError(t, err)
+
+	packet := rtpPacket(1, 100, 0x65, 1, 2, 3)
+	protected, err := endpoint.protectRTP(packet)
+	require.NoError(t, err)
+	unprotected, err := peer.unprotectRTP(protected)
+	require.NoError(t, err, "the endpoint's outbound key is the peer's inbound key")
+	require.Equal(t, packet, unprotected)
+	_, err = endpoint.unprotectRTP(protected)
+	require.ErrorIs(t, err, errSRTPAuth, "packets received use the inbound key")
+
+	protected, err = peer.protectRTCP(rtcpBYEPacket(0x1234, ""))
+	require.NoError(t, err)
+	_, err = endpoint.unprotectRTCP(protected)
+	require.NoError(t, err)
+
+	_, err = newSRTPContext(&pb.SrtpCrypto{Suite: "AES_CM_128_HMAC_SHA1_80", KeyParams: outbound, InboundKeyParams: "inline:AAAA"})
+	require.Error(t, err)
+}
+
+func TestSRTPRetransmit(t *testing.T) {
+	rtpConn := listenLocalUDP(t)
+	client := listenLocalUDP(t)
+
+	nack, err := newNACKCache(16)
+	require.NoError(t, err)
+	stream := &Stream{nack: nack, stats: newCounters()}
+	srtp, _ := testSRTPContext(t, 1, "AES_CM_128_HMAC_SHA1_80")
+	endpoint := &Endpoint{enabled: true, address: *client.LocalAddr().(*net.UDPAddr), srtp: srtp, srtpSent: nack.clone()}
+
+	packet := rtpPacket(7, 100, 0x65, 1, 2, 3)
+	stream.nack.store(packet)
+	require.NoError(t, endpoint.writeRTP(rtpConn, packet))
+	retransmit(rtpConn, 26, stream, endpoint, []uint16{7})
+
+	buffer := make([]byte, 1500)
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := client.Read(buffer)
+	require.NoError(t, err)
+	sent := append([]byte(nil), buffer[:n]...)
+	n, err = client.Read(buffer)
+	require.NoError(t, err)
+	require.Equal(t, sent, buffer[:n], "the packet is resent as it was protected")
+	require.Equal(t, uint64(1), stream.stats.snapshot()["retransmits"])
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+// registeredStream is the request that created a stream and the requests that
+// added its clients, updated by later requests.
+type registeredStream struct {
+	create  *pb.StreamData
+	clients map[string]*pb.StreamData
+}
+
+// streamRegistry tracks the control requests that the streams can be
+// recreated from, and saves them to its file after every change.
+// Its streams, generation and file are protected by streamsLock.
+type streamRegistry struct {
+	file       string
+	generation uint64
+	streams    map[uint32]*registeredStream
+	// saves wakes up the saver after changes, saving orders the saves
+	saves  chan struct{}
+	saving sync.Mutex
+}
+
+// newStreamRegistry returns an empty registry. Its generation starts from the
+// time, so that a registry whose file is lost doesn't repeat the generations
+// that the controller has seen.
+func newStreamRegistry() *streamRegistry {
+	return &streamRegistry{
+		generation: uint64(time.Now().UnixNano()),
+		streams:    make(map[uint32]*registeredStream),
+		saves:      make(chan struct{}, 1),
+	}
+}
+
+var registry = newStreamRegistry()
+
+// record applies a request that changed the streams, then saves the registry
+// if the request changed it.
+func (r *streamRegistry) record(in *pb.StreamData) {
+	if r.apply(in) {
+		r.changed()
+	}
+}
+
+// apply updates the registry with a request and reports whether it changed.
+func (r *streamRegistry) apply(in *pb.StreamData) bool {
+	stream := r.streams[in.Id]
+	if stream == nil && in.Operation != pb.StreamOperation_CREATE {
+		return false
+	}
+	switch in.Operation {
+	case pb.StreamOperation_CREATE:
+		r.streams[in.Id] = &registeredStream{create: proto.Clone(in).(*pb.StreamData), clients: make(map[string]*pb.StreamData)}
+	case pb.StreamOperation_UPDATE:
+		if proto.Equal(stream.create.Endpoint, in.Endpoint) {
+			return false
+		}
+		stream.create.Endpoint = proto.Clone(in.Endpoint).(*pb.Endpoint)
+	case pb.StreamOperation_DELETE:
+		delete(r.streams, in.Id)
+	case pb.StreamOperation_ADD_EP:
+		key := clientKey(in)
+		if client, ok := stream.clients[key]; ok && proto.Equal(client, in) {
+			return false
+		}
+		stream.clients[key] = proto.Clone(in).(*pb.StreamData)
+	case pb.StreamOperation_UPD_EP:
+		client, ok := stream.clients[clientKey(in)]
+		if !ok || client.Enable == in.Enable {
+			return false
+		}
+		client.Enable = in.Enable
+	case pb.StreamOperation_DEL_EP:
+		key := clientKey(in)
+		if _, ok := stream.clients[key]; !ok {
+			return false
+		}
+		delete(stream.clients, key)
+	}
+	return true
+}
+
+// forgetStream removes a stream that the data plane removed by itself.
+func (r *streamRegistry) forgetStream(streamID uint32) {
+	if _, ok := r.streams[streamID]; ok {
+		delete(r.streams, streamID)
+		r.changed()
+	}
+}
+
+// forgetClient removes a client that the data plane removed by itself.
+func (r *streamRegistry) forgetClient(streamID uint32, key string) {
+	if stream, ok := r.streams[streamID]; ok {
+		if _, ok := stream.clients[key]; ok {
+			delete(stream.clients, key)
+			r.changed()
+		}
+	}
+}
+
+// changed moves to the next generation and wakes up the saver, which saves
+// the changes made until it runs at once.
+func (r *streamRegistry) changed() {
+	r.generation++
+	r.wake()
+}
+
+// wake wakes up the saver, if the registry is saved.
+func (r *streamRegistry) wake() {
+	if r.file == "" {
+		return
+	}
+	select {
+	case r.saves <- struct{}{}:
+	default:
+	}
+}
+
+// saveChanges saves the registry after changes, until the process exits.
+func (r *streamRegistry) saveChanges() {
+	for range r.saves {
+		if err := r.save(); err != nil {
+			log.WithError(err).Error("Could not save streams")
+		}
+	}
+}
+
+// snapshot returns the requests that recreate the streams, in stream ID order.
+func (r *streamRegistry) snapshot() *pb.Registry {
+	snapshot := &pb.Registry{Generation: r.generation}
+	streamIDs := make([]uint32, 0, len(r.streams))
+	for streamID := range r.streams {
+		streamIDs = append(streamIDs, streamID)
+	}
+	slices.Sort(streamIDs)
+	for _, streamID := range streamIDs {
+		stream := r.streams[streamID]
+		snapshot.Requests = append(snapshot.Requests, stream.create)
+		keys := make([]string, 0, len(stream.clients))
+		for key := range stream.clients {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			snapshot.Requests = append(snapshot.Requests, stream.clients[key])
+		}
+	}
+	return snapshot
+}
+
+// save atomically replaces the file with the registry's snapshot, which is
+// taken with streamsLock held and written without it. The file holds SRTP
+// keys, so only the owner can read it.
+// Must be called without streamsLock held.
+func (r *streamRegistry) save() error {
+	r.saving.Lock()
+	defer r.saving.Unlock()
+	streamsLock.RLock()
+	file := r.file
+	data, err := protojson.Marshal(r.snapshot())
+	streamsLock.RUnlock()
+	if file == "" || err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// stopSaving stops saving to the file and waits for a save in progress, and
+// returns the file to resume saving to.
+// Must be called without streamsLock held.
+func (r *streamRegistry) stopSaving() string {
+	streamsLock.Lock()
+	file := r.file
+	r.file = ""
+	streamsLock.Unlock()
+	r.saving.Lock()
+	defer r.saving.Unlock()
+	return file
+}
+
+// restore recreates the streams saved in the file, but those exchanging SRTP
+// whose packet indexes would start over and reuse their keystreams, which the
+// controller must rekey.
+// Must be called before the server accepts requests.
+func (r *streamRegistry) restore(s *server) error {
+	data, err := os.ReadFile(r.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	snapshot := &pb.Registry{}
+	if err := protojson.Unmarshal(data, snapshot); err != nil {
+		return err
+	}
+	requests := snapshot.Requests[:0]
+	for _, in := range snapshot.Requests {
+		if hasSRTP(in) {
+			log.Warnf("Not restoring %v request of stream %v, its SRTP keys must be renewed", in.Operation, in.Id)
+			continue
+		}
+		requests = append(requests, in)
+	}
+	snapshot.Requests = requests
+	r.replay(s, snapshot, r.file)
+
+	// the generations of the last changes may have been replied before they
+	// were saved, so the controller resyncs to one that was never issued
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	r.generation = max(r.generation, uint64(time.Now().UnixNano()))
+	r.changed()
+	log.Infof("Registry generation moved to %d", r.generation)
+	return nil
+}
+
+func hasSRTP(in *pb.StreamData) bool {
+	for _, endpoint := range append(append([]*pb.Endpoint{in.Endpoint}, in.RedundantSources...), in.BackupSources...) {
+		if endpoint.GetSrtp() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// replay recreates the streams of a snapshot. The generation is kept if
+// every stream and client is recreated, and changed otherwise so that the
+// controller resyncs.
+func (r *streamRegistry) replay(s *server, snapshot *pb.Registry, from string) {
+	streamsLock.Lock()
+	defer streamsLock.Unlock()
+	failed := 0
+	for _, in := range snapshot.Requests {
+		result, changed := s.streamAddDel(in)
+		if !changed || result.ErrorMessage != "" {
+			log.Warnf("Could not restore %v request of stream %v: %s", in.Operation, in.Id, result.ErrorMessage)
+			failed++
+			continue
+		}
+		r.apply(in)
+	}
+	restoreStreamStates(snapshot.States)
+	r.generation = snapshot.Generation
+	log.Infof("Restored %d streams from %s, generation %d", len(r.streams), from, r.generation)
+	if failed > 0 {
+		r.changed()
+	}
+}
+
+func (s *server) RegistryGeneration(context.Context, *pb.RegistryGenerationRequest) (*pb.RegistryGeneration, error) {
+	streamsLock.RLock()
+	defer streamsLock.RUnlock()
+	return &pb.RegistryGeneration{Generation: registry.generation}, nil
+}
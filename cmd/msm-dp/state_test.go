@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func useRegistry(t *testing.T, file string) {
+	previous := registry
+	registry = newStreamRegistry()
+	registry.file = file
+	t.Cleanup(func() { registry = previous })
+}
+
+func TestRegistryRestore(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.json")
+	useRegistry(t, file)
+	defer func() {
+		streamsLock.Lock()
+		deleteStream(18)
+		streamsLock.Unlock()
+	}()
+
+	s := &server{}
+	ctx := context.Background()
+	base := registry.generation
+	for i, in := range []*pb.StreamData{
+		{Id: 18, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8100}},
+		{Id: 18, Operation: pb.StreamOperation_ADD_EP, Enable: true, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8102}},
+		{Id: 18, Operation: pb.StreamOperation_ADD_EP, Enable: true, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8104}},
+		{Id: 18, Operation: pb.StreamOperation_UPD_EP, Enable: false, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8102}},
+		{Id: 18, Operation: pb.StreamOperation_DEL_EP, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8104}},
+	} {
+		result, err := s.StreamAddDel(ctx, in)
+		require.NoError(t, err)
+		require.Empty(t, result.ErrorMessage)
+		require.Equal(t, base+uint64(i+1), result.Generation)
+	}
+	for _, in := range []*pb.StreamData{
+		{Id: 20, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8102}},
+		{Id: 20, Operation: pb.StreamOperation_DELETE},
+		{Id: 18, Operation: pb.StreamOperation_UPD_EP, Enable: false, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8102}},
+		{Id: 18, Operation: pb.StreamOperation_DEL_EP, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8104}},
+	} {
+		result, err := s.StreamAddDel(ctx, in)
+		require.NoError(t, err)
+		require.Equal(t, base+5, result.Generation, "requests that change nothing don't change the generation")
+	}
+
+	require.Len(t, registry.saves, 1, "the changes are saved at once")
+	<-registry.saves
+	require.NoError(t, registry.save())
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the file holds SRTP keys")
+
+	// restart
+	streamsLock.Lock()
+	deleteStream(18)
+	streamsLock.Unlock()
+	useRegistry(t, file)
+	require.NoError(t, registry.restore(s))
+
+	generation, err := s.RegistryGeneration(ctx, &pb.RegistryGenerationRequest{})
+	require.NoError(t, err)
+	require.Greater(t, generation.Generation, base+5, "changes replied before they were saved aren't issued again")
+	streamsLock.RLock()
+	stream := streams[18]
+	require.NotNil(t, stream)
+	require.Len(t, stream.clients, 1)
+	client := stream.clients[clientKey(&pb.StreamData{Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8102}})]
+	require.NotNil(t, client)
+	require.False(t, client.enabled)
+	streamsLock.RUnlock()
+}
+
+func TestRegistryRestoreFailure(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.json")
+	data, err := protojson.Marshal(&pb.Registry{Generation: 7, Requests: []*pb.StreamData{
+		{Id: 19, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8106}},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, data, 0o600))
+	useRegistry(t, file)
+
+	require.NoError(t, registry.restore(&server{}))
+	require.Greater(t, registry.generation, uint64(7), "the controller must resync")
+	require.NoError(t, registry.save())
+	saved := &pb.Registry{}
+	data, err = os.ReadFile(file)
+	require.NoError(t, err)
+	require.NoError(t, protojson.Unmarshal(data, saved))
+	require.Equal(t, registry.generation, saved.Generation)
+	require.Empty(t, saved.Requests)
+}
+
+func TestRegistryRestoreMissingFile(t *testing.T) {
+	useRegistry(t, filepath.Join(t.TempDir(), "state.json"))
+	seeded := registry.generation
+	require.NoError(t, registry.restore(&server{}))
+	require.Equal(t, seeded, registry.generation)
+	require.Greater(t, seeded, uint64(time.Hour), "a lost file doesn't start the generations over")
+}
+
+func TestRegistryRestoreSRTP(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "state.json")
+	_, crypto := testSRTPContext(t, 1, "AES_CM_128_HMAC_SHA1_80")
+	data, err := protojson.Marshal(&pb.Registry{Generation: 7, Requests: []*pb.StreamData{
+		{Id: 29, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8120}},
+		{Id: 29, Operation: pb.StreamOperation_ADD_EP, Enable: true, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8122}},
+		{Id: 29, Operation: pb.StreamOperation_ADD_EP, Enable: true, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8124, Srtp: crypto}},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, data, 0o600))
+	useRegistry(t, file)
+	defer func() {
+		streamsLock.Lock()
+		deleteStream(29)
+		streamsLock.Unlock()
+	}()
+
+	require.NoError(t, registry.restore(&server{}))
+	require.Greater(t, registry.generation, uint64(7), "the controller must rekey the SRTP clients")
+	streamsLock.RLock()
+	defer streamsLock.RUnlock()
+	require.Len(t, streams[29].clients, 1)
+	require.Contains(t, streams[29].clients, "127.0.0.1:8122")
+}
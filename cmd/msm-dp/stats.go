@@ -0,0 +1,29 @@
+package main
+
+import "sync"
+
+// counters is a set of named per-stream counters reported by StreamStats.
+type counters struct {
+	mu     sync.Mutex
+	values map[string]uint64
+}
+
+func newCounters() *counters {
+	return &counters{values: make(map[string]uint64)}
+}
+
+func (c *counters) add(name string, delta uint64) {
+	c.mu.Lock()
+	c.values[name] += delta
+	c.mu.Unlock()
+}
+
+func (c *counters) snapshot() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values := make(map[string]uint64, len(c.values))
+	for name, value := range c.values {
+		values[name] = value
+	}
+	return values
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"net"
+	"net/netip"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+// streamStates returns the forwarding state of the streams, which the
+// control requests don't recreate. streamsLock must be held.
+func streamStates() []*pb.StreamState {
+	var states []*pb.StreamState
+	for streamID, stream := range streams {
+		state := &pb.StreamState{
+			Id:           streamID,
+			ActiveSource: uint32(stream.active),
+			Ended:        stream.ended,
+			Rewriter:     stream.rewriter.state(),
+			Translator:   stream.translator.state(),
+		}
+		for _, source := range stream.sources {
+			state.SourceSrtp = append(state.SourceSrtp, source.srtp.state())
+		}
+		for key, endpoint := range stream.clients {
+			state.Clients = append(state.Clients, endpoint.state(key))
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// restoreStreamStates continues the streams recreated from the control
+// requests from their handed off forwarding state. streamsLock must be held.
+func restoreStreamStates(states []*pb.StreamState) {
+	for _, state := range states {
+		stream, ok := streams[state.Id]
+		if !ok {
+			continue
+		}
+		if int(state.ActiveSource) < len(stream.sources) {
+			stream.active = int(state.ActiveSource)
+		}
+		stream.ended = state.Ended
+		stream.rewriter.restore(state.Rewriter)
+		stream.translator.restore(state.Translator)
+		for i, srtp := range state.SourceSrtp {
+			if i < len(stream.sources) {
+				stream.sources[i].srtp.restore(srtp)
+			}
+		}
+		for _, client := range state.Clients {
+			endpoint, ok := stream.clients[client.Key]
+			if !ok {
+				log.Warnf("Could not restore the state of client %s of stream %v", client.Key, state.Id)
+				continue
+			}
+			endpoint.restore(client)
+		}
+	}
+}
+
+func (e *Endpoint) state(key string) *pb.ClientState {
+	state := &pb.ClientState{
+		Key:     key,
+		Enabled: e.enabled,
+		Packets: e.packets,
+		Octets:  e.octets,
+		Srtp:    e.srtp.state(),
+	}
+	if e.latch != nil {
+		state.Address = e.address.String()
+		if e.rtcp != nil {
+			state.RtcpAddress = e.rtcp.String()
+		}
+		state.Latched = e.latch.latched
+		state.RtpLatched = e.latch.rtpLatched
+		state.RtcpLatched = e.latch.rtcpLatched
+	}
+	return state
+}
+
+func (e *Endpoint) restore(state *pb.ClientState) {
+	e.enabled = state.Enabled
+	e.packets = state.Packets
+	e.octets = state.Octets
+	e.srtp.restore(state.Srtp)
+	if e.latch == nil {
+		return
+	}
+	if address, err := netip.ParseAddrPort(state.Address); err == nil {
+		e.address = *net.UDPAddrFromAddrPort(address)
+	}
+	if address, err := netip.ParseAddrPort(state.RtcpAddress); err == nil {
+		e.rtcp = net.UDPAddrFromAddrPort(address)
+	}
+	e.latch.latched = state.Latched
+	e.latch.rtpLatched = state.RtpLatched
+	e.latch.rtcpLatched = state.RtcpLatched
+}
+
+func (c *srtpContext) state() *pb.SrtpState {
+	if c == nil {
+		return nil
+	}
+	return &pb.SrtpState{Sent: ssrcStates(c.sent), Received: ssrcStates(c.received)}
+}
+
+func (c *srtpContext) restore(state *pb.SrtpState) {
+	if c == nil || state == nil {
+		return
+	}
+	restoreSSRCStates(c.sent, state.Sent)
+	restoreSSRCStates(c.received, state.Received)
+}
+
+func ssrcStates(states map[uint32]*srtpState) []*pb.SrtpSsrcState {
+	var result []*pb.SrtpSsrcState
+	for ssrc, s := range states {
+		result = append(result, &pb.SrtpSsrcState{
+			Ssrc:       ssrc,
+			Started:    s.started,
+			Roc:        s.roc,
+			Seq:        uint32(s.seq),
+			Replay:     s.replay.state(),
+			RtcpIndex:  s.rtcpIndex,
+			RtcpReplay: s.rtcpReplay.state(),
+		})
+	}
+	return result
+}
+
+func restoreSSRCStates(states map[uint32]*srtpState, from []*pb.SrtpSsrcState) {
+	for _, s := range from {
+		states[s.Ssrc] = &srtpState{
+			started:    s.Started,
+			roc:        s.Roc,
+			seq:        uint16(s.Seq),
+			replay:     restoreReplayWindow(s.Replay),
+			rtcpIndex:  s.RtcpIndex,
+			rtcpReplay: restoreReplayWindow(s.RtcpReplay),
+		}
+	}
+}
+
+func (w *replayWindow) state() *pb.ReplayWindow {
+	return &pb.ReplayWindow{Started: w.started, Highest: w.highest, Bitmap: w.bitmap}
+}
+
+func restoreReplayWindow(state *pb.ReplayWindow) replayWindow {
+	return replayWindow{started: state.GetStarted(), highest: state.GetHighest(), bitmap: state.GetBitmap()}
+}
+
+func (r *rtpRewriter) state() *pb.RewriterState {
+	if r == nil {
+		return nil
+	}
+	return &pb.RewriterState{
+		Ssrc:      r.ssrc,
+		Started:   r.started,
+		Switching: r.switching,
+		InSsrc:    r.inSSRC,
+		SeqOffset: uint32(r.seqOffset),
+		TsOffset:  r.tsOffset,
+		LastSeq:   uint32(r.lastSeq),
+		LastTs:    r.lastTS,
+		LastTime:  unixNanos(r.lastTime),
+	}
+}
+
+func (r *rtpRewriter) restore(state *pb.RewriterState) {
+	if r == nil || state == nil {
+		return
+	}
+	r.ssrc = state.Ssrc
+	r.started = state.Started
+	r.switching = state.Switching
+	r.inSSRC = state.InSsrc
+	r.seqOffset = uint16(state.SeqOffset)
+	r.tsOffset = state.TsOffset
+	r.lastSeq = uint16(state.LastSeq)
+	r.lastTS = state.LastTs
+	r.lastTime = fromUnixNanos(state.LastTime)
+}
+
+func (t *rtcpTranslator) state() *pb.TranslatorState {
+	if t == nil {
+		return nil
+	}
+	return &pb.TranslatorState{
+		Ssrc:       t.ssrc,
+		SourceSsrc: t.sourceSSRC,
+		OutSsrc:    t.outSSRC,
+		SrNtp:      t.srNTP,
+		SrRtp:      t.srRTP,
+		SrTime:     unixNanos(t.srTime),
+		LastRtp:    t.lastRTP,
+		LastTime:   unixNanos(t.lastTime),
+		Source: &pb.ReceiverState{
+			Started:       t.source.started,
+			BaseSeq:       uint32(t.source.baseSeq),
+			MaxSeq:        uint32(t.source.maxSeq),
+			BadSeq:        t.source.badSeq,
+			Cycles:        t.source.cycles,
+			Received:      t.source.received,
+			ExpectedPrior: t.source.expectedPrior,
+			ReceivedPrior: t.source.receivedPrior,
+			Transit:       t.source.transit,
+			Jitter:        t.source.jitter,
+			LastSr:        t.source.lastSR,
+			LastSrTime:    unixNanos(t.source.lastSRTime),
+		},
+	}
+}
+
+func (t *rtcpTranslator) restore(state *pb.TranslatorState) {
+	if t == nil || state == nil {
+		return
+	}
+	t.ssrc = state.Ssrc
+	t.sourceSSRC = state.SourceSsrc
+	t.outSSRC = state.OutSsrc
+	t.srNTP = state.SrNtp
+	t.srRTP = state.SrRtp
+	t.srTime = fromUnixNanos(state.SrTime)
+	t.lastRTP = state.LastRtp
+	t.lastTime = fromUnixNanos(state.LastTime)
+	source := state.GetSource()
+	t.source = receiverStats{
+		started:       source.GetStarted(),
+		baseSeq:       uint16(source.GetBaseSeq()),
+		maxSeq:        uint16(source.GetMaxSeq()),
+		badSeq:        source.GetBadSeq(),
+		cycles:        source.GetCycles(),
+		received:      source.GetReceived(),
+		expectedPrior: source.GetExpectedPrior(),
+		receivedPrior: source.GetReceivedPrior(),
+		transit:       source.GetTransit(),
+		jitter:        source.GetJitter(),
+		lastSR:        source.GetLastSr(),
+		lastSRTime:    fromUnixNanos(source.GetLastSrTime()),
+	}
+}
+
+// unixNanos returns the time in Unix nanoseconds, 0 for the zero time.
+func unixNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNanos(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
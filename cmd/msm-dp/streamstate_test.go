@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+func TestStreamStates(t *testing.T) {
+	useRegistry(t, "")
+	_, crypto := testSRTPContext(t, 1, "AES_CM_128_HMAC_SHA1_80")
+	srtpClient := &pb.StreamData{Id: 30, Operation: pb.StreamOperation_ADD_EP, Enable: true, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8132, Srtp: crypto}}
+	latchedClient := &pb.StreamData{
+		Id: 30, Operation: pb.StreamOperation_ADD_EP, Endpoint: &pb.Endpoint{Ip: "0.0.0.0", Port: 0},
+		Enable: true, Latch: pb.LatchMode_LATCH_SSRC, LatchSsrc: 0x3333,
+	}
+	s := &server{}
+	for _, in := range []*pb.StreamData{
+		{Id: 30, Operation: pb.StreamOperation_CREATE, Endpoint: &pb.Endpoint{Ip: "127.0.0.1", Port: 8130}, RewriteSsrc: true, TerminateRtcp: true},
+		srtpClient,
+		latchedClient,
+	} {
+		result, err := s.StreamAddDel(context.Background(), in)
+		require.NoError(t, err)
+		require.Empty(t, result.ErrorMessage)
+	}
+	defer func() {
+		streamsLock.Lock()
+		deleteStream(30)
+		streamsLock.Unlock()
+	}()
+
+	streamsLock.Lock()
+	stream := streams[30]
+	packet := rtpPacketFrom(0x1234, 7, 100)
+	// handed off times have no monotonic clock reading
+	stream.rewriter.rewrite(packet, time.Now().Round(0))
+	endpoint := stream.clients[clientKey(srtpClient)]
+	_, err := endpoint.srtp.protectRTP(packet)
+	require.NoError(t, err)
+	sent := endpoint.srtp.sent[stream.rewriter.ssrc]
+	sent.roc = 3
+	require.True(t, latchClient(rtpPacketFrom(0x3333, 1, 0), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8134}, false))
+	stream.clients[clientKey(latchedClient)].packets = 5
+	rewriter := *stream.rewriter
+	translatorSSRC := stream.translator.ssrc
+
+	snapshot := registry.snapshot()
+	snapshot.States = streamStates()
+	data, err := protojson.Marshal(snapshot)
+	require.NoError(t, err)
+	deleteStream(30)
+	streamsLock.Unlock()
+
+	handedOff := &pb.Registry{}
+	require.NoError(t, protojson.Unmarshal(data, handedOff))
+	registry.replay(s, handedOff, "the test")
+
+	streamsLock.RLock()
+	defer streamsLock.RUnlock()
+	stream = streams[30]
+	require.Equal(t, rewriter, *stream.rewriter, "the rewritten SSRC and sequence numbers carry on")
+	require.Equal(t, translatorSSRC, stream.translator.ssrc)
+	require.Equal(t, *sent, *streams[30].clients[clientKey(srtpClient)].srtp.sent[rewriter.ssrc], "the SRTP indexes carry on")
+	latched := stream.clients[clientKey(latchedClient)]
+	require.True(t, latched.latch.latched)
+	require.True(t, latched.enabled)
+	require.Equal(t, 8134, latched.address.Port)
+	require.EqualValues(t, 5, latched.packets)
+}
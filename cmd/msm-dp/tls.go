@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// certReloader serves the current certificate and client CAs, reloaded when
+// the files change.
+type certReloader struct {
+	certFile, keyFile, caFile string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes [3]time.Time
+}
+
+func newCertReloader(certFile, keyFile, caFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// fileModTimes returns the modification times of the files, following symlinks
+// so that rotated Kubernetes secrets are noticed.
+func (r *certReloader) fileModTimes() ([3]time.Time, error) {
+	var modTimes [3]time.Time
+	for i, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// reload loads the files if they changed since they were last loaded. The
+// current certificate is kept if the new files can't be loaded.
+func (r *certReloader) reload() error {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		return err
+	}
+	r.mu.RLock()
+	unchanged := r.cert != nil && modTimes == r.modTimes
+	r.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	pem, err := os.ReadFile(r.caFile)
+	if err != nil {
+		return err
+	}
+	clientCA := x509.NewCertPool()
+	if !clientCA.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no CA certificates in %s", r.caFile)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCA = clientCA
+	r.modTimes = modTimes
+	r.mu.Unlock()
+	log.Infof("Loaded TLS certificate %s and client CA %s", r.certFile, r.caFile)
+	return nil
+}
+
+// watch reloads the files periodically.
+func (r *certReloader) watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := r.reload(); err != nil {
+			log.WithError(err).Error("Could not reload TLS certificate, keeping the current one")
+		}
+	}
+}
+
+// config returns the TLS configuration of new connections, which requires a
+// client certificate signed by the client CA.
+func (r *certReloader) config() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientCAs:    r.clientCA,
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+			}, nil
+		},
+	}
+}
+
+// serverCredentials returns the gRPC server options for the TLS flags, with
+// no options when TLS isn't configured.
+func serverCredentials() ([]grpc.ServerOption, error) {
+	if *tlsCert == "" && *tlsKey == "" && *tlsCA == "" {
+		log.Warn("gRPC server is not using TLS, any host that can reach it can control streams")
+		return nil, nil
+	}
+	if *tlsCert == "" || *tlsKey == "" || *tlsCA == "" {
+		return nil, errors.New("tlsCert, tlsKey and tlsCA must be set together")
+	}
+	reloader, err := newCertReloader(*tlsCert, *tlsKey, *tlsCA)
+	if err != nil {
+		return nil, err
+	}
+	go reloader.watch(*tlsReloadInterval)
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(reloader.config()))}, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/media-streaming-mesh/msm-dp/api/v1alpha1/msm_dp"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCert issues a certificate for 127.0.0.1, self-signed if parent is nil.
+func newTestCert(t *testing.T, serial int64, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "msm-dp test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// write writes the certificate and key as PEM files, modified at the given time.
+func (c *testCert) write(t *testing.T, certFile, keyFile string, modTime time.Time) {
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	if keyFile == "" {
+		return
+	}
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	ca := newTestCert(t, 1, nil)
+	ca.write(t, caFile, "", time.Now())
+	newTestCert(t, 2, ca).write(t, certFile, keyFile, time.Now())
+	client := newTestCert(t, 3, ca)
+
+	reloader, err := newCertReloader(certFile, keyFile, caFile)
+	require.NoError(t, err)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer(grpc.Creds(credentials.NewTLS(reloader.config())))
+	pb.RegisterMsmDataPlaneServer(s, &server{})
+	go s.Serve(lis)
+	defer s.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	call := func(certificates ...tls.Certificate) error {
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			RootCAs: roots, Certificates: certificates,
+		})))
+		require.NoError(t, err)
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, err = pb.NewMsmDataPlaneClient(conn).StreamStats(ctx, &pb.StreamStatsRequest{Id: 9999})
+		return err
+	}
+	require.Equal(t, codes.NotFound, status.Code(call(client.tlsCertificate())), "clients with a certificate are served")
+	require.Error(t, call())
+	require.NotEqual(t, codes.NotFound, status.Code(call()), "clients without a certificate are rejected")
+	other := newTestCert(t, 4, nil)
+	require.NotEqual(t, codes.NotFound, status.Code(call(newTestCert(t, 5, other).tlsCertificate())), "clients of other CAs are rejected")
+
+	serverSerial := func() int64 {
+		conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client.tlsCertificate()}})
+		require.NoError(t, err)
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+	}
+	require.Equal(t, int64(2), serverSerial())
+	newTestCert(t, 6, ca).write(t, certFile, keyFile, time.Now().Add(time.Minute))
+	require.NoError(t, reloader.reload())
+	require.Equal(t, int64(6), serverSerial(), "rotated certificates are served to new connections")
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0o600))
+	require.NoError(t, os.Chtimes(keyFile, time.Now().Add(2*time.Minute), time.Now().Add(2*time.Minute)))
+	require.Error(t, reloader.reload())
+	require.Equal(t, int64(6), serverSerial(), "the current certificate is kept when the new one is invalid")
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// rtcpInterval is the interval between RTCP reports generated by msm-dp.
+const rtcpInterval = 5 * time.Second
+
+const (
+	maxDropout  = 3000
+	maxMisorder = 100
+)
+
+// receiverStats tracks the reception of an RTP source as described in RFC 3550 §A.1 and §A.8.
+type receiverStats struct {
+	started       bool
+	baseSeq       uint16
+	maxSeq        uint16
+	badSeq        uint32
+	cycles        uint32
+	received      uint32
+	expectedPrior uint32
+	receivedPrior uint32
+	transit       int64
+	jitter        float64
+
+	lastSR     uint32
+	lastSRTime time.Time
+}
+
+func (s *receiverStats) init(seq uint16) {
+	*s = receiverStats{started: true, baseSeq: seq, maxSeq: seq, badSeq: 1<<16 + 1, lastSR: s.lastSR, lastSRTime: s.lastSRTime}
+}
+
+// update records the arrival of an RTP packet and reports whether it has a valid sequence number.
+func (s *receiverStats) update(seq uint16, timestamp uint32, arrival time.Time, clockRate uint32) bool {
+	if !s.started {
+		s.init(seq)
+	} else if delta := seq - s.maxSeq; delta < maxDropout {
+		if seq < s.maxSeq {
+			s.cycles += 1 << 16
+		}
+		s.maxSeq = seq
+	} else if delta <= 1<<16-maxMisorder {
+		// a large jump, accept it if the source has restarted
+		if uint32(seq) != s.badSeq {
+			s.badSeq = uint32(seq+1) & 0xffff
+			return false
+		}
+		s.init(seq)
+	}
+	s.received++
+
+	arrivalTS := int64(float64(arrival.UnixNano()) * float64(clockRate) / float64(time.Second))
+	transit := arrivalTS - int64(timestamp)
+	if s.received > 1 {
+		d := transit - s.transit
+		if d < 0 {
+			d = -d
+		}
+		s.jitter += (float64(d) - s.jitter) / 16
+	}
+	s.transit = transit
+	return true
+}
+
+func (s *receiverStats) expected() uint32 {
+	return s.cycles + uint32(s.maxSeq) - uint32(s.baseSeq) + 1
+}
+
+// lost returns the cumulative number of packets lost.
+func (s *receiverStats) lost() int64 {
+	if !s.started {
+		return 0
+	}
+	return int64(s.expected()) - int64(s.received)
+}
+
+// srReceived records the arrival of a sender report for LSR and DLSR.
+func (s *receiverStats) srReceived(ntp uint64, arrival time.Time) {
+	s.lastSR = uint32(ntp >> 16)
+	s.lastSRTime = arrival
+}
+
+// reportBlock returns an RTCP reception report block for the source and
+// starts a new reporting interval.
+func (s *receiverStats) reportBlock(ssrc uint32, now time.Time) []byte {
+	block := make([]byte, 24)
+	binary.BigEndian.PutUint32(block[0:4], ssrc)
+
+	expected := s.expected()
+	expectedInterval := expected - s.expectedPrior
+	receivedInterval := s.received - s.receivedPrior
+	s.expectedPrior = expected
+	s.receivedPrior = s.received
+	if lostInterval := int64(expectedInterval) - int64(receivedInterval); expectedInterval > 0 && lostInterval > 0 {
+		block[4] = byte(lostInterval << 8 / int64(expectedInterval))
+	}
+
+	lost := s.lost()
+	if lost > 0x7fffff {
+		lost = 0x7fffff
+	} else if lost < -0x800000 {
+		lost = -0x800000
+	}
+	block[5], block[6], block[7] = byte(lost>>16), byte(lost>>8), byte(lost)
+	binary.BigEndian.PutUint32(block[8:12], s.cycles+uint32(s.maxSeq))
+	binary.BigEndian.PutUint32(block[12:16], uint32(s.jitter))
+	if !s.lastSRTime.IsZero() {
+		binary.BigEndian.PutUint32(block[16:20], s.lastSR)
+		binary.BigEndian.PutUint32(block[20:24], uint32(now.Sub(s.lastSRTime)*65536/time.Second))
+	}
+	return block
+}
+
+// toNTP converts a time to the 64 bit NTP timestamp format.
+func toNTP(t time.Time) uint64 {
+	const ntpEpochOffset = 2208988800
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+// rtcpTranslator terminates RTCP for a stream as described in RFC 3550 §7:
+// it generates sender reports towards the clients and receiver reports
+// towards the source instead of forwarding the source's RTCP.
+type rtcpTranslator struct {
+	// ssrc identifies msm-dp in receiver reports sent to the source
+	ssrc      uint32
+	cname     string
+	clockRate uint32
+
+	sourceSSRC uint32
+	source     receiverStats
+	// outSSRC is the SSRC of the forwarded packets, which differs from the
+	// source's SSRC when the stream is rewritten
+	outSSRC uint32
+
+	// mapping between NTP and RTP time from the last sender report of the source
+	srNTP  uint64
+	srRTP  uint32
+	srTime time.Time
+
+	// the last forwarded RTP packet, used when there is no sender report
+	lastRTP  uint32
+	lastTime time.Time
+}
+
+func newRTCPTranslator(streamID uint32, clockRate uint32) *rtcpTranslator {
+	if clockRate == 0 {
+		clockRate = defaultClockRate
+	}
+	hostname, _ := os.Hostname()
+	return &rtcpTranslator{
+		ssrc:      rand.Uint32(),
+		cname:     fmt.Sprintf("msm-dp-%d@%s", streamID, hostname),
+		clockRate: clockRate,
+	}
+}
+
+// received records an RTP packet as sent by the source.
+func (t *rtcpTranslator) received(packet []byte, arrival time.Time) {
+	if ssrc := rtpSSRC(packet); ssrc != t.sourceSSRC || !t.source.started {
+		t.sourceSSRC = ssrc
+		t.source = receiverStats{}
+		t.srTime = time.Time{}
+	}
+	t.source.update(rtpSequence(packet), rtpTimestamp(packet), arrival, t.clockRate)
+}
+
+// forwarded records an RTP packet as sent to the clients.
+func (t *rtcpTranslator) forwarded(packet []byte, now time.Time) {
+	t.outSSRC = rtpSSRC(packet)
+	t.lastRTP = rtpTimestamp(packet)
+	t.lastTime = now
+}
+
+// sourceRTCP processes a compound RTCP packet from the source. The packet
+// must already have been rewritten to the outbound SSRC and timestamps.
+func (t *rtcpTranslator) sourceRTCP(compound []byte, now time.Time) {
+	forEachRTCP(compound, func(packetType, _ byte, packet []byte) {
+		if packetType != rtcpSR || len(packet) < 28 || binary.BigEndian.Uint32(packet[4:8]) != t.outSSRC {
+			return
+		}
+		ntp := binary.BigEndian.Uint64(packet[8:16])
+		t.srNTP = ntp
+		t.srRTP = binary.BigEndian.Uint32(packet[16:20])
+		t.srTime = now
+		t.source.srReceived(ntp, now)
+	})
+}
+
+// senderReport builds a compound SR and SDES packet for a client.
+func (t *rtcpTranslator) senderReport(ssrc uint32, endpoint *Endpoint, now time.Time) []byte {
+	var ntp uint64
+	var rtp uint32
+	if !t.srTime.IsZero() {
+		elapsed := now.Sub(t.srTime)
+		ntp = t.srNTP + uint64(elapsed)<<32/uint64(time.Second)
+		rtp = t.srRTP + uint32(elapsed.Seconds()*float64(t.clockRate))
+	} else {
+		ntp = toNTP(now)
+		rtp = t.lastRTP + uint32(now.Sub(t.lastTime).Seconds()*float64(t.clockRate))
+	}
+
+	sr := make([]byte, 28)
+	sr[0] = 0x80
+	sr[1] = rtcpSR
+	binary.BigEndian.PutUint16(sr[2:4], 6)
+	binary.BigEndian.PutUint32(sr[4:8], ssrc)
+	binary.BigEndian.PutUint64(sr[8:16], ntp)
+	binary.BigEndian.PutUint32(sr[16:20], rtp)
+	binary.BigEndian.PutUint32(sr[20:24], endpoint.packets)
+	binary.BigEndian.PutUint32(sr[24:28], endpoint.octets)
+	return append(sr, sdesCNAME(ssrc, t.cname)...)
+}
+
+// receiverReport builds a compound RR and SDES packet for the source.
+func (t *rtcpTranslator) receiverReport(now time.Time) []byte {
+	rr := make([]byte, 8, 32)
+	rr[0] = 0x81
+	rr[1] = rtcpRR
+	binary.BigEndian.PutUint16(rr[2:4], 7)
+	binary.BigEndian.PutUint32(rr[4:8], t.ssrc)
+	rr = append(rr, t.source.reportBlock(t.sourceSSRC, now)...)
+	return append(rr, sdesCNAME(t.ssrc, t.cname)...)
+}
+
+// sdesCNAME builds an SDES packet with a single CNAME item.
+func sdesCNAME(ssrc uint32, cname string) []byte {
+	if len(cname) > 255 {
+		cname = cname[:255]
+	}
+	length := (4 + 2 + len(cname) + 4) &^ 3
+	sdes := make([]byte, 4+length)
+	sdes[0] = 0x81
+	sdes[1] = rtcpSDES
+	binary.BigEndian.PutUint16(sdes[2:4], uint16(length/4))
+	binary.BigEndian.PutUint32(sdes[4:8], ssrc)
+	sdes[8] = 1
+	sdes[9] = byte(len(cname))
+	copy(sdes[10:], cname)
+	return sdes
+}
+
+// sendRTCPReports periodically sends the RTCP reports of the streams that terminate RTCP.
+func sendRTCPReports(rtcpConn *net.UDPConn) {
+	ticker := time.NewTicker(rtcpInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		streamsLock.Lock()
+		for _, stream := range streams {
+			if stream.translator != nil {
+				sendStreamReports(rtcpConn, stream, now)
+			}
+		}
+		streamsLock.Unlock()
+	}
+}
+
+func sendStreamReports(rtcpConn *net.UDPConn, stream *Stream, now time.Time) {
+	t := stream.translator
+	if !t.source.started {
+		return
+	}
+
+	rr := t.receiverReport(now)
+	for _, src := range stream.sources {
+		sourceRTCP := net.UDPAddr{IP: src.address.IP, Port: src.address.Port + 1, Zone: src.address.Zone}
+		packet := rr
+		if src.srtp != nil {
+			var err error
+			if packet, err = src.srtp.protectRTCP(rr); err != nil {
+				log.WithError(err).Warn("Could not protect RTCP receiver report.")
+				continue
+			}
+		}
+		if _, _, err := rtcpConn.WriteMsgUDP(packet, trafficClass(sourceRTCP.IP, stream.dscp), &sourceRTCP); err != nil {
+			log.WithError(err).Warn("Could not send RTCP receiver report.")
+		}
+	}
+
+	for _, endpoint := range stream.clients {
+		if !endpoint.enabled {
+			continue
+		}
+		if err := endpoint.writeRTCP(rtcpConn, t.senderReport(t.outSSRC, endpoint, now)); err != nil {
+			log.WithError(err).Warn("Could not send RTCP sender report.")
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiverStatsLoss(t *testing.T) {
+	var stats receiverStats
+	now := time.Now()
+	for seq := uint16(65530); seq != 10; seq++ {
+		if seq == 65534 || seq == 2 {
+			continue
+		}
+		require.True(t, stats.update(seq, uint32(seq)*3000, now, 90000))
+	}
+	require.Equal(t, int64(2), stats.lost())
+
+	block := stats.reportBlock(0xcafe, now)
+	require.Equal(t, uint32(0xcafe), binary.BigEndian.Uint32(block[0:4]))
+	require.Equal(t, byte(2*256/16), block[4], "fraction lost")
+	require.Equal(t, []byte{0, 0, 2}, block[5:8], "cumulative lost")
+	require.Equal(t, uint32(1<<16+9), binary.BigEndian.Uint32(block[8:12]), "extended highest sequence number")
+
+	require.False(t, stats.update(30000, 0, now, 90000), "large jumps are only accepted when repeated")
+	require.True(t, stats.update(30001, 0, now, 90000))
+	require.Equal(t, int64(0), stats.lost())
+}
+
+func TestToNTP(t *testing.T) {
+	ntp := toNTP(time.Date(1970, 1, 1, 0, 0, 1, 500000000, time.UTC))
+	require.Equal(t, uint64(2208988801), ntp>>32)
+	require.Equal(t, uint64(1<<31), ntp&0xffffffff)
+}
+
+func TestTranslatorSenderReport(t *testing.T) {
+	translator := newRTCPTranslator(7, 90000)
+	now := time.Now()
+	packet := rtpPacket(1, 90000)
+	translator.received(packet, now)
+	translator.forwarded(packet, now)
+
+	endpoint := &Endpoint{}
+	endpoint.sent(rtpPacket(1, 90000, 1, 2, 3, 4))
+	endpoint.sent(rtpPacket(2, 90000, 1, 2, 3, 4))
+
+	report := translator.senderReport(translator.outSSRC, endpoint, now.Add(time.Second))
+	var types []byte
+	require.True(t, forEachRTCP(report, func(packetType, _ byte, p []byte) {
+		types = append(types, packetType)
+		if packetType == rtcpSR {
+			require.Equal(t, uint32(0x12345678), binary.BigEndian.Uint32(p[4:8]))
+			require.Equal(t, uint32(2*90000), binary.BigEndian.Uint32(p[16:20]))
+			require.Equal(t, uint32(2), binary.BigEndian.Uint32(p[20:24]))
+			require.Equal(t, uint32(8), binary.BigEndian.Uint32(p[24:28]))
+		}
+	}))
+	require.Equal(t, []byte{rtcpSR, rtcpSDES}, types)
+
+	rr := translator.receiverReport(now)
+	require.True(t, forEachRTCP(rr, func(packetType, count byte, p []byte) {
+		if packetType == rtcpRR {
+			require.Equal(t, byte(1), count)
+			require.Equal(t, translator.ssrc, binary.BigEndian.Uint32(p[4:8]))
+			require.Equal(t, uint32(0x12345678), binary.BigEndian.Uint32(p[8:12]))
+		}
+	}))
+}
+
+func TestTranslatorDropsShortPackets(t *testing.T) {
+	stream := &Stream{sources: []*source{newSource("127.0.0.1", 5000)}, translator: newRTCPTranslator(7, 90000), stats: newCounters()}
+	forwardStreamRTP(nil, 7, stream, stream.sources[0], []byte{0x80, 96, 0})
+	require.Equal(t, uint64(1), stream.stats.snapshot()["malformed_packets"])
+	require.Zero(t, stream.translator.source.received)
+}